@@ -1,8 +1,10 @@
 package cmap
 
 import "bytes"
+import "context"
 import "math"
 import "sync/atomic"
+import "time"
 import "unsafe"
 
 
@@ -27,10 +29,8 @@ func NewCMap[T uint32 | uint64]() *CMap[T] {
 
 	hashChunks := int(math.Pow(float64(2), float64(bitChunkSize))) / bitChunkSize
 
-	rootNode := &CMapNode[T]{
-		IsLeaf: false,
-		Bitmap: 0,
-		Children: []*CMapNode[T]{},
+	rootNode := &cMapRoot[T]{
+		CMapNode: CMapNode[T]{ Bitmap: 0, Children: []CMapChildNode{} },
 	}
 
 	return &CMap[T]{
@@ -40,8 +40,26 @@ func NewCMap[T uint32 | uint64]() *CMap[T] {
 	}
 }
 
+// newLike 
+//	Creates an empty map with the configuration of this map, for maps made from its nodes. 
+//	The Metrics, Backoff, MaxRetries and Clock are shared, while the change log and retained versions belong to this map and are not.
+//
+// Returns:
+//	The new map
+func (cMap *CMap[T]) newLike() *CMap[T] {
+	newMap := NewCMap[T]()
+	newMap.Metrics = cMap.Metrics
+	newMap.Backoff = cMap.Backoff
+	newMap.MaxRetries = cMap.MaxRetries
+	newMap.Clock = cMap.Clock
+
+	return newMap
+}
+
 // NewLeafNode 
-//	Creates a new leaf node in the hash array mapped trie, which stores a key value pair.
+//	Creates a new leaf node in the hash array mapped trie, which stores a key value pair. 
+//	Small key-value pairs are copied inline into the leaf, otherwise the leaf references the incoming key and value.
+//	A nil value is stored as an empty value at either size, so a present key never reads back as nil.
 //
 // Parameters:
 //	key: the incoming key to be inserted
@@ -49,12 +67,17 @@ func NewCMap[T uint32 | uint64]() *CMap[T] {
 //
 // Returns:
 //	A new leaf node in the hash array mapped trie
-func (cMap *CMap[T]) NewLeafNode(key []byte, value []byte) *CMapNode[T] {
-	return &CMapNode[T]{
-		IsLeaf: true,
-		Key: key,
-		Value: value,
+func (cMap *CMap[T]) NewLeafNode(key []byte, value []byte) CMapLeafNode {
+	if len(key) + len(value) <= inlineLeafSize {
+		leaf := &cMapInlineLeaf{ keyLen: uint8(len(key)), valueLen: uint8(len(value)) }
+		copy(leaf.data[:], key)
+		copy(leaf.data[len(key):], value)
+
+		return leaf
 	}
+
+	if value == nil { value = []byte{} }
+	return &CMapLeaf{ key: key, value: value }
 }
 
 // NewInternalNode 
@@ -63,17 +86,16 @@ func (cMap *CMap[T]) NewLeafNode(key []byte, value []byte) *CMapNode[T] {
 // Returns:
 //	A new internal node with bitmap initialized to 0 and an empty array of child nodes
 func (cMap *CMap[T]) NewInternalNode() *CMapNode[T] {
-	return &CMapNode[T]{
-		IsLeaf: false,
-		Bitmap: 0,
-		Children: []*CMapNode[T]{},
-	}
+	newINode := cMap.allocNode(0)
+	newINode.Bitmap = 0
+
+	return newINode
 }
 
 // CopyNode 
 //	Creates a copy of an existing node. 
 //	This is used for path copying, so on operations that modify the trie, a copy is created instead of modifying the existing node. 
-//	The data structure is essentially immutable. If an operation succeeds, the copy replaces the existing node, otherwise the copy is discarded.
+//	The data structure is essentially immutable. If an operation succeeds, the copy replaces the existing node, otherwise the copy is discarded and reused by a later copy.
 //
 // Parameters:
 //	node: the existing node to create a copy of
@@ -81,13 +103,8 @@ func (cMap *CMap[T]) NewInternalNode() *CMapNode[T] {
 // Returns:
 //	A copy of the existing node within the hash array mapped trie, which the operation will modify
 func (cMap *CMap[T]) CopyNode(node *CMapNode[T]) *CMapNode[T] {
-	nodeCopy := &CMapNode[T]{
-		Key: node.Key,
-		Value: node.Value,
-		IsLeaf: node.IsLeaf,
-		Bitmap: node.Bitmap,
-		Children: make([]*CMapNode[T], len(node.Children)),
-	}
+	nodeCopy := cMap.allocNode(len(node.Children))
+	nodeCopy.Bitmap = node.Bitmap
 
 	copy(nodeCopy.Children, node.Children)
 
@@ -97,81 +114,197 @@ func (cMap *CMap[T]) CopyNode(node *CMapNode[T]) *CMapNode[T] {
 // Put 
 //	Inserts or updates key-value pair into the hash array mapped trie. 
 //	The operation begins at the root of the trie and traverses through the tree until the correct location is found, copying the entire path. 
-//	If the operation fails, the copied and modified path is discarded and the operation retries back at the root until completed.
+//	If the compare and swap on the root fails, only the part of the copied path above the level where the conflict occurred is rebuilt, waiting between attempts using the Backoff strategy of the map.
 //
 // Parameters:
 //	key: the key in the key-value pair
 //	value: the value in the key-value pair
 //
 // Returns:
-//	truthy on successful completion
+//	truthy on successful completion, or falsey if MaxRetries was exceeded
 func (cMap *CMap[T]) Put(key []byte, value []byte) bool {
-	for {
-		completed := cMap.PutRecursive(&cMap.Root, key, value, 0)
-		if completed { return true }
+	return cMap.PutContext(context.Background(), key, value) == nil
+}
+
+// PutContext 
+//	Same as Put, but the retry loop is aborted if the context is cancelled.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//
+// Returns:
+//	nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (cMap *CMap[T]) PutContext(ctx context.Context, key []byte, value []byte) error {
+	_, err := cMap.update(ctx, PutOp, key, func(CMapLeafNode) (CMapLeafNode, bool) {
+		return cMap.NewLeafNode(key, value), true
+	})
+
+	return err
+}
+
+// update 
+//	Runs an update function on the leaf for a key and path copies the result up to the root. 
+//	This is the shared mutation for Put and Delete, and for conditional operations that depend on the existing leaf. 
+//	The copied path is kept across attempts so that on a failed compare and swap only the levels that changed are rebuilt. 
+//	If the trie has a change feed, the change is published to it after the compare and swap succeeds.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	op: the operation being performed, used for instrumentation
+//	key: the key being modified
+//	updateFn: receives the existing leaf for the key, or nil if the key does not exist, and returns the new leaf, or nil to delete the key. Returning falsey leaves the trie unchanged
+//
+// Returns:
+//	The change made to the leaf for the key by the successful attempt, and nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (cMap *CMap[T]) update(ctx context.Context, op CMapOp, key []byte, updateFn func(existing CMapLeafNode) (CMapLeafNode, bool)) (cMapChange, error) {
+	return cMap.updateIf(ctx, op, key, nil, updateFn)
+}
+
+// updateIf 
+//	Same as update, but each attempt first checks a guard against the root it loaded, and the update is dropped once the guard rejects it. 
+//	Since the compare and swap is against that root, the guard holds on the root the update replaces.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	op: the operation being performed, used for instrumentation
+//	key: the key being modified
+//	guard: checked against the root loaded by each attempt, returning falsey to drop the update. If nil, every root is accepted
+//	updateFn: see update
+//
+// Returns:
+//	The change made to the leaf for the key by the successful attempt, which is not applied if the guard rejected the root, and the error, see update
+func (cMap *CMap[T]) updateIf(ctx context.Context, op CMapOp, key []byte, guard func(root *cMapRoot[T]) bool, updateFn func(existing CMapLeafNode) (CMapLeafNode, bool)) (cMapChange, error) {
+	path := cMap.newPath(key)
+	path.guard = guard
+	err := cMap.retry(ctx, op, func() bool {
+		return cMap.attemptMutation(path, updateFn)
+	})
+
+	if err != nil { 
+		cMap.releaseCopies(path.copies)
+		return cMapChange{}, err 
 	}
+
+	if path.change.applied && path.root.feed != nil { path.root.feed.publish(path.root.seq, path.change) }
+	return path.change, nil
 }
 
-// PutRecursive
-//	Attempts to traverse through the trie, locating the node at a given level to modify for the key-value pair. 
-//	It first hashes the key, determines the sparse index in the bitmap to modify, and createsa copy of the current node to be modified. 
-//	If the bit in the bitmap of the node is not set, a new leaf node is created, the bitmap of the copy is modified to reflect the position of the new leaf node, and the child node array is extended to include the new leaf node. 
-//	Then, an atomic compare and swap operation is performed where the operation attempts to replace the current node with the modified copy. 
-//	If the operation succeeds the response is returned by moving back up the tree. If it fails, the copy is discarded and the operation returns to the root to be reattempted. 
-//	If the current bit is set in the bitmap, the operation checks if the node at the location in the child node array is a leaf node or an internal node. 
-//	If it is a leaf node and the key is the same as the incoming key, the copy is modified with the new value and we attempt to compare and swap the current child leaf node with the new copy. 
-//	If the leaf node does not contain the same key, the operation creates a new internal node, and inserts the new leaf node for the incoming key and value as well as the existing child node into the new internal node.
-//	Attempts to compare and swap the current leaf node with the new internal node containing the existing child node and the new leaf node for the incoming key and value. 
-//	If the node is an internal node, the operation traverses down the tree to the internal node and the above steps are repeated until the key-value pair is inserted.
+// updateAtNode
+//	Inserts, updates, or removes the leaf for a key in the node at the bottom of the path to the key, where the slot for the key is either empty or holds a leaf or collision node. 
+//	A copy of the node is created to be modified. 
+//	If the bit in the bitmap of the node is not set, the bitmap of the copy is modified to reflect the position of the new leaf node, and the child node array is extended to include the new leaf node. 
+//	If the bit is set and the leaf node contains the same key, the leaf is replaced in the copy with the new leaf node, or removed by updating the bitmap and shrinking the table. 
+//	If the leaf node does not contain the same key, the leaf is replaced with a new internal node containing both the existing leaf node and the new leaf node.
+//	If the slot holds a collision node, the leaf is added to or removed from a copy of the collision node.
+//
+//	The change made by the update function is recorded on the path.
 //
 // Parameters:
-//	node: the node in the tree where the operation is currently
-//	key: the key for the incoming key-value pair
-//	value: the value for the incoming key-value pair
-//	level: the current level in the tree the operation is at
+//	path: the path of the mutation
+//	node: the node at the bottom of the path to the key
+//	level: the level of the node within the trie
+//	updateFn: receives the existing leaf and returns the new leaf, or nil to delete the key. Returning falsey leaves the node unchanged
 //
 // Returns:
-//	truthy value from successful or failed compare and swap operations
-func (cMap *CMap[T]) PutRecursive(node *unsafe.Pointer, key []byte, value []byte, level int) bool {
-	hash := cMap.CalculateHashForCurrentLevel(key, level)
+//	The modified copy of the node, or nil if there is nothing to modify
+func (cMap *CMap[T]) updateAtNode(path *cMapPath[T], node *CMapNode[T], level int, updateFn func(existing CMapLeafNode) (CMapLeafNode, bool)) *CMapNode[T] {
+	key := path.key
+	hash := cMap.pathHash(path, level)
 	index := cMap.getSparseIndex(hash, level)
 
-	currNode := (*CMapNode[T])(atomic.LoadPointer(node))
-	nodeCopy := cMap.CopyNode(currNode)
+	applyFn := func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		newLeaf, ok := updateFn(existing)
+		path.change = cMapChange{ existing: existing, leaf: newLeaf, applied: ok && (existing != nil || newLeaf != nil) }
 
-	if ! IsBitSet(nodeCopy.Bitmap, index) {
-		newLeaf := cMap.NewLeafNode(key, value)
-		nodeCopy.Bitmap = SetBit(nodeCopy.Bitmap, index)
-		pos := cMap.getPosition(nodeCopy.Bitmap, hash, level)
-		nodeCopy.Children = ExtendTable(nodeCopy.Children, nodeCopy.Bitmap, pos, newLeaf)
+		return newLeaf, path.change.applied
+	}
 
-		return cMap.compareAndSwap(node, currNode, nodeCopy)
-	} else {
-		pos := cMap.getPosition(nodeCopy.Bitmap, hash, level)
-		childNode := nodeCopy.Children[pos]
-
-		if childNode.IsLeaf {
-			if bytes.Equal(key, childNode.Key) {
-				nodeCopy.Children[pos].Value = value
-				return cMap.compareAndSwap(node, currNode, nodeCopy)
-			} else {
-				newINode := cMap.NewInternalNode()
-				iNodePtr := unsafe.Pointer(newINode)
-
-				cMap.PutRecursive(&iNodePtr, childNode.Key, childNode.Value, level + 1)
-				cMap.PutRecursive(&iNodePtr, key, value, level + 1)
-
-				nodeCopy.Children[pos] = (*CMapNode[T])(atomic.LoadPointer(&iNodePtr))
-				return cMap.compareAndSwap(node, currNode, nodeCopy)
+	if ! IsBitSet(node.Bitmap, index) {
+		newLeaf, ok := applyFn(nil)
+		if ! ok { return nil }
+
+		bitMap := SetBit(node.Bitmap, index)
+		pos := cMap.getPosition(bitMap, hash, level)
+
+		return cMap.copyNodeWithInsert(node, bitMap, pos, newLeaf)
+	}
+
+	pos := cMap.getPosition(node.Bitmap, hash, level)
+
+	switch childNode := node.Children[pos].(type) {
+		case *CMapCollision:
+			idx := childNode.find(key)
+
+			var existing CMapLeafNode
+			if idx != -1 { existing = childNode.Leaves[idx] }
+
+			newLeaf, ok := applyFn(existing)
+			if ! ok { return nil }
+
+			nodeCopy := cMap.CopyNode(node)
+			if newLeaf == nil {
+				nodeCopy.Children[pos] = childNode.withoutLeaf(idx)
+			} else { nodeCopy.Children[pos] = childNode.withLeaf(newLeaf) }
+
+			return nodeCopy
+		case CMapLeafNode:
+			if ! bytes.Equal(key, childNode.Key()) {
+				newLeaf, ok := applyFn(nil)
+				if ! ok { return nil }
+
+				nodeCopy := cMap.CopyNode(node)
+				nodeCopy.Children[pos] = cMap.splitLeaf(childNode, newLeaf, level + 1)
+
+				return nodeCopy
 			}
-		} else {
-			childPtr := unsafe.Pointer(nodeCopy.Children[pos])
-			cMap.PutRecursive(&childPtr, key, value, level + 1)
 
-			nodeCopy.Children[pos] = (*CMapNode[T])(atomic.LoadPointer(&childPtr))
-			return cMap.compareAndSwap(node, currNode, nodeCopy)
-		}
+			newLeaf, ok := applyFn(childNode)
+			if ! ok { return nil }
+			if newLeaf == nil { return cMap.copyNodeWithRemove(node, SetBit(node.Bitmap, index), pos) }
+
+			nodeCopy := cMap.CopyNode(node)
+			nodeCopy.Children[pos] = newLeaf
+
+			return nodeCopy
 	}
+
+	return nil
+}
+
+// splitLeaf
+//	Creates a new internal node containing two leaf nodes whose keys share the same sparse index at the level above. 
+//	If the sparse indexes of the keys are also equal at this level, another internal node is created a level below until the keys diverge. 
+//	If the keys have not diverged by the maximum level of the trie, a collision node is created instead.
+//
+// Parameters:
+//	existing: the leaf node already in the trie
+//	newLeaf: the new leaf node being inserted
+//	level: the level of the new internal node within the trie
+//
+// Returns:
+//	The new internal node, or collision node, containing both leaf nodes
+func (cMap *CMap[T]) splitLeaf(existing CMapLeafNode, newLeaf CMapLeafNode, level int) CMapChildNode {
+	if level > cMap.maxLevel() { return &CMapCollision{ Leaves: []CMapLeafNode{ existing, newLeaf } } }
+
+	newINode := cMap.NewInternalNode()
+
+	existingIndex := cMap.getSparseIndex(cMap.CalculateHashForCurrentLevel(existing.Key(), level), level)
+	newIndex := cMap.getSparseIndex(cMap.CalculateHashForCurrentLevel(newLeaf.Key(), level), level)
+
+	if existingIndex == newIndex {
+		newINode.Bitmap = SetBit(newINode.Bitmap, existingIndex)
+		newINode.Children = append(newINode.Children, cMap.splitLeaf(existing, newLeaf, level + 1))
+	} else {
+		newINode.Bitmap = SetBit(SetBit(newINode.Bitmap, existingIndex), newIndex)
+		
+		if existingIndex < newIndex {
+			newINode.Children = append(newINode.Children, existing, newLeaf)
+		} else { newINode.Children = append(newINode.Children, newLeaf, existing) }
+	}
+
+	return newINode
 }
 
 // Get 
@@ -181,128 +314,119 @@ func (cMap *CMap[T]) PutRecursive(node *unsafe.Pointer, key []byte, value []byte
 // Returns:
 //	either the value for the key in byte array representation or nil if the key does not exist
 func (cMap *CMap[T]) Get(key []byte) []byte {
+	if cMap.Metrics != nil { defer cMap.observe(GetOp, time.Now(), new(int), new(error)) }
 	return cMap.GetRecursive(&cMap.Root, key, 0)
 }
 
 // GetRecursive 
-//	Attempts to recursively retrieve a value for a given key within the hash array mapped trie. 
+//	Attempts to retrieve a value for a given key within the hash array mapped trie, starting from the node at the given level. 
+//	Since the trie utilizes path copying, any threads modifying the trie are modifying copies so it the get operation returns the value at the point in time of the get operation. 
+//	If the leaf for the key has expired, the key is treated as missing and the expired leaf is lazily deleted.
+//
+// Parameters:
+//	node: the pointer to the node to be checked for the key-value pair
+//	key: the key being searched for
+//	level: the current level within the trie the operation is at
+//
+// Returns:
+//	either the value for the given key or nil if non-existent or expired
+func (cMap *CMap[T]) GetRecursive(node *unsafe.Pointer, key []byte, level int) []byte {
+	leaf := cMap.getLeafRecursive((*CMapNode[T])(atomic.LoadPointer(node)), key, level)
+	if leaf == nil { return nil }
+
+	if _, expiring := leaf.(*cMapExpiringLeaf); expiring && cMap.isExpired(leaf, cMap.now()) {
+		cMap.deleteExpired(leaf)
+		return nil
+	}
+
+	return leaf.Value()
+}
+
+// getLeafRecursive 
+//	Attempts to recursively retrieve the leaf for a given key within the hash array mapped trie. 
 //	For each node traversed to at each level the operation travels to, the sparse index is calculated for the hashed key. 
 //	If the bit is not set in the bitmap, return nil since the key has not been inserted yet into the trie. 
 //	Otherwise, determine the position in the child node array for the sparse index. 
-//	If the child node is a leaf node and the key to be searched for is the same as the key of the child node, the value has been found. 
-//	Since the trie utilizes path copying, any threads modifying the trie are modifying copies so it the get operation returns the value at the point in time of the get operation. 
+//	If the child node is a leaf node and the key to be searched for is the same as the key of the child node, the leaf has been found, and if the keys differ the key does not exist. 
+//	If the child node is a collision node, its leaves are searched for the key. 
 //	If the node is node a leaf node, but instead an internal node, recurse down the path to the next level to the child node in the position of the child node array and repeat the above.
 //
 // Parameters:
-//	node: the pointer to the node to be checked for the key-value pair
+//	node: the node to be checked for the key-value pair
 //	key: the key being searched for
 //	level: the current level within the trie the operation is at
 //
 // Returns:
-//	either the value for the given key or nil if non-existent or if the node is being modified
-func (cMap *CMap[T]) GetRecursive(node *unsafe.Pointer, key []byte, level int) []byte {
+//	either the leaf for the given key or nil if non-existent
+func (cMap *CMap[T]) getLeafRecursive(node *CMapNode[T], key []byte, level int) CMapLeafNode {
 	hash := cMap.CalculateHashForCurrentLevel(key, level)
 	index := cMap.getSparseIndex(hash, level)
-	currNode := (*CMapNode[T])(atomic.LoadPointer(node))
 
-	if ! IsBitSet(currNode.Bitmap, index) {
-		return nil
-	} else {
-		pos := cMap.getPosition(currNode.Bitmap, hash, level)
-		childNode := currNode.Children[pos]
-
-		if childNode.IsLeaf && bytes.Equal(key, childNode.Key) {
-			return childNode.Value
-		} else {
-			childPtr := unsafe.Pointer(currNode.Children[pos])
-			return cMap.GetRecursive(&childPtr, key, level + 1)
-		}
+	if ! IsBitSet(node.Bitmap, index) { return nil }
+	
+	pos := cMap.getPosition(node.Bitmap, hash, level)
+
+	switch childNode := node.Children[pos].(type) {
+		case *CMapNode[T]:
+			return cMap.getLeafRecursive(childNode, key, level + 1)
+		case *CMapCollision:
+			idx := childNode.find(key)
+			if idx == -1 { return nil }
+
+			return childNode.Leaves[idx]
+		case CMapLeafNode:
+			if bytes.Equal(key, childNode.Key()) { return childNode }
 	}
+
+	return nil
 }
 
 // Delete 
 //	Attempts to delete a key-value pair within the hash array mapped trie. 
-//	It starts at the root of the trie and recurses down the path to the key to be deleted. If the operation succeeds truthy value is returned, otherwise the operation returns to the root to retry the operation.
+//	It starts at the root of the trie and moves down the path to the key to be deleted, copying the path and removing internal nodes that become empty. 
+//	If the operation succeeds truthy value is returned, otherwise the operation waits using the Backoff strategy of the map and retries from the level where the conflict occurred.
 //
 // Parameters:
 //	key: the key to attempt to delete
 //
 // Returns:
-//	truthy on successful completion
+//	truthy on successful completion, or falsey if MaxRetries was exceeded
 func (cMap *CMap[T]) Delete(key []byte) bool {
-	for {
-		completed := cMap.DeleteRecursive(&cMap.Root, key, 0)
-		if completed { return true }
-	}
+	return cMap.DeleteContext(context.Background(), key) == nil
 }
 
-// DeleteRecursive 
-//	Attempts to recursively move down the path of the trie to the key-value pair to be deleted. 
-//	The hash for the key is calculated, the sparse index in the bitmap is determined for the given level, and a copy of the current node is created to be modifed. 
-//	If the bit in the bitmap is not set, the key doesn't exist so truthy is returned since there is nothing to delete and the operation completes. 
-//	If the bit is set, the child node for the position within the child node array is found. 
-//	If the child node is a leaf node and the key of the child node is equal to the key of the key to delete, the copy is modified to update the bitmap and shrink the table and remove the given node. 
-//	A compare and swap operation is performed, and if successful traverse back up the trie and complete, otherwise the operation is returned to the root to retry. 
-//	If the child node is an internal node, the operation recurses down the trie to the next level. 
-//	On return, if the internal node is empty, the copy modified so the bitmap is updated and table is shrunk. 
-//	A compare and swap operation is performed on the current node with the new copy.
+// DeleteContext 
+//	Same as Delete, but the retry loop is aborted if the context is cancelled.
 //
 // Parameters:
-//	node: a pointer to the node that is being modified
-//	key: the key to be deleted
-//	level: the current level within the trie
-// 
+//	ctx: the context for the operation
+//	key: the key to attempt to delete
+//
 // Returns:
-//	truthy response on success and falsey on failure
-func (cMap *CMap[T]) DeleteRecursive(node *unsafe.Pointer, key []byte, level int) bool {
-	hash := cMap.CalculateHashForCurrentLevel(key, level)
-	index := cMap.getSparseIndex(hash, level)
-
-	currNode := (*CMapNode[T])(atomic.LoadPointer(node))
-	nodeCopy := cMap.CopyNode(currNode)
+//	nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (cMap *CMap[T]) DeleteContext(ctx context.Context, key []byte) error {
+	_, err := cMap.update(ctx, DeleteOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		return nil, existing != nil
+	})
 
-	if ! IsBitSet(nodeCopy.Bitmap, index) {
-		return true
-	} else {
-		pos := cMap.getPosition(nodeCopy.Bitmap, hash, level)
-		childNode := nodeCopy.Children[pos]
-
-		if childNode.IsLeaf {
-			if bytes.Equal(key, childNode.Key) {
-				nodeCopy.Bitmap = SetBit(nodeCopy.Bitmap, index)
-				nodeCopy.Children = ShrinkTable(nodeCopy.Children, nodeCopy.Bitmap, pos)
-
-				return cMap.compareAndSwap(node, currNode, nodeCopy)
-			}
-
-			return false
-		} else {
-			childPtr := unsafe.Pointer(nodeCopy.Children[pos])
-			cMap.DeleteRecursive(&childPtr, key, level + 1)
-
-			popCount := calculateHammingWeight(nodeCopy.Bitmap)
-			if popCount == 0 {
-				nodeCopy.Bitmap = SetBit(nodeCopy.Bitmap, index)
-				nodeCopy.Children = ShrinkTable(nodeCopy.Children, nodeCopy.Bitmap, pos)
-			}
-
-			return cMap.compareAndSwap(node, currNode, nodeCopy)
-		}
-	}
+	return err
 }
 
-// compareAndSwap
-//	Performs CAS opertion.
+// Len 
+//	The total key-value pairs in the trie. The count is kept on the root, so it is exact for the root at the point in time of the operation. 
+//	Expired keys are counted until they are deleted.
 //
-// Parameters:
-//	node: the node to be updated
-//	currNode: the original node to be updated
-//	nodeCopy: the copy to swap the original with
+// Returns:
+//	The total key-value pairs
+func (cMap *CMap[T]) Len() int {
+	return int(cMap.loadRoot().size)
+}
+
+// Bytes 
+//	The total bytes held in keys and values in the trie, kept on the root like Len.
 //
 // Returns:
-//	true on successful CAS and false on failure
-func (cMap *CMap[T]) compareAndSwap(node *unsafe.Pointer, currNode *CMapNode[T], nodeCopy *CMapNode[T]) bool {
-	if atomic.CompareAndSwapPointer(node, unsafe.Pointer(currNode), unsafe.Pointer(nodeCopy)) {
-		return true
-	} else { return false }
+//	The total bytes held in keys and values
+func (cMap *CMap[T]) Bytes() int64 {
+	return cMap.loadRoot().bytes
 }
\ No newline at end of file
@@ -0,0 +1,89 @@
+package cmap
+
+import "context"
+import "errors"
+import "math/rand"
+import "runtime"
+import "time"
+
+
+//========================================= CMap Backoff
+
+
+// ErrMaxRetriesExceeded is returned when an operation fails to complete within the MaxRetries of the map
+var ErrMaxRetriesExceeded = errors.New("cmap: max retries exceeded")
+
+
+// Wait 
+//	Returns immediately, unless the context is already cancelled.
+func (backoff NoBackoff) Wait(ctx context.Context, attempt int) error {
+	return ctx.Err()
+}
+
+// Wait 
+//	Busy spins for 2^attempt * SpinIterations iterations while attempt is within Spins, otherwise yields the processor with runtime.Gosched.
+func (backoff SpinYieldBackoff) Wait(ctx context.Context, attempt int) error {
+	if attempt <= backoff.Spins {
+		shift := attempt
+		if shift > 16 { shift = 16 }
+
+		iterations := backoff.SpinIterations << shift
+		for idx := 0; idx < iterations; idx++ {}
+	} else { runtime.Gosched() }
+
+	return ctx.Err()
+}
+
+// Wait 
+//	Sleeps for a random duration between half of and the full exponential delay, Base * 2^(attempt - 1) capped at Max. 
+//	The sleep is interrupted if the context is cancelled.
+func (backoff ExponentialBackoff) Wait(ctx context.Context, attempt int) error {
+	shift := attempt - 1
+	if shift > 32 { shift = 32 }
+
+	delay := backoff.Base << shift
+	if delay <= 0 || (backoff.Max > 0 && delay > backoff.Max) { delay = backoff.Max }
+	if delay <= 0 { return ctx.Err() }
+
+	jittered := delay / 2 + time.Duration(rand.Int63n(int64(delay / 2) + 1))
+
+	timer := time.NewTimer(jittered)
+	defer timer.Stop()
+
+	select {
+		case <- ctx.Done():
+			return ctx.Err()
+		case <- timer.C:
+			return nil
+	}
+}
+
+// retry 
+//	The retry loop shared by mutating operations. 
+//	The attempt is run until it succeeds, with the Backoff strategy of the map run between attempts. 
+//	The loop is aborted if the context is cancelled or MaxRetries is exceeded.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	op: the operation being retried, used for instrumentation
+//	attempt: a single attempt of the operation, returning truthy on a successful compare and swap
+//
+// Returns:
+//	nil on success, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (cMap *CMap[T]) retry(ctx context.Context, op CMapOp, attempt func() bool) (err error) {
+	retries := 0
+	if cMap.Metrics != nil { defer cMap.observe(op, time.Now(), &retries, &err) }
+
+	for {
+		if err := ctx.Err(); err != nil { return err }
+		if attempt() { return nil }
+
+		if cMap.Metrics != nil { cMap.Metrics.ObserveCASFailure(op) }
+		if cMap.MaxRetries > 0 && retries >= cMap.MaxRetries { return ErrMaxRetriesExceeded }
+		
+		retries++
+		if cMap.Backoff != nil {
+			if err := cMap.Backoff.Wait(ctx, retries); err != nil { return err }
+		}
+	}
+}
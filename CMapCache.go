@@ -0,0 +1,213 @@
+package cmap
+
+import "context"
+import "math/rand"
+
+
+//========================================= CMap Cache
+
+
+// defaultSampleSize is the number of keys sampled for each eviction when SampleSize is not set
+const defaultSampleSize = 5
+
+
+// NewCMapCache 
+//	Creates a hash array mapped trie bounded by a maximum number of entries, a maximum number of bytes, or both.
+//
+// Parameters:
+//	maxEntries: the maximum key-value pairs in the cache. If 0, the entries are not bounded
+//	maxBytes: the maximum bytes held in keys and values in the cache. If 0, the bytes are not bounded
+//
+// Returns:
+//	The new cache
+func NewCMapCache[T uint32 | uint64](maxEntries int, maxBytes int64) *CMapCache[T] {
+	return &CMapCache[T]{
+		CMap: NewCMap[T](),
+		MaxEntries: maxEntries,
+		MaxBytes: maxBytes,
+		SampleSize: defaultSampleSize,
+	}
+}
+
+// Put 
+//	Inserts or updates a key-value pair, marking the key as the most recently used. 
+//	If the cache is over capacity after the insert, keys are evicted until it is back within capacity.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//
+// Returns:
+//	truthy on successful completion, or falsey if MaxRetries of the underlying trie was exceeded
+func (cache *CMapCache[T]) Put(key []byte, value []byte) bool {
+	return cache.PutContext(context.Background(), key, value) == nil
+}
+
+// PutContext 
+//	Same as Put, but the retry loop is aborted if the context is cancelled.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//
+// Returns:
+//	nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (cache *CMapCache[T]) PutContext(ctx context.Context, key []byte, value []byte) error {
+	leaf := &cMapCacheLeaf{ key: key, value: value }
+	leaf.accessed.Store(cache.tick.Add(1))
+
+	_, err := cache.CMap.update(ctx, PutOp, key, func(CMapLeafNode) (CMapLeafNode, bool) {
+		return leaf, true
+	})
+
+	if err != nil { return err }
+
+	for cache.overCapacity() {
+		if ! cache.evict() { break }
+	}
+
+	return nil
+}
+
+// Get 
+//	Retrieves the value for a key, marking the key as the most recently used.
+//
+// Parameters:
+//	key: the key being searched for
+//
+// Returns:
+//	either the value for the given key or nil if non-existent
+func (cache *CMapCache[T]) Get(key []byte) []byte {
+	leaf := cache.CMap.getLeafRecursive(&cache.CMap.loadRoot().CMapNode, key, 0)
+	if leaf == nil {
+		cache.misses.Add(1)
+		return nil
+	}
+
+	cache.hits.Add(1)
+	if cacheLeaf, ok := leaf.(*cMapCacheLeaf); ok { cacheLeaf.accessed.Store(cache.tick.Add(1)) }
+
+	return leaf.Value()
+}
+
+// Delete 
+//	Deletes a key-value pair from the cache. Deleted keys are not passed to OnEvict.
+//
+// Parameters:
+//	key: the key to delete
+//
+// Returns:
+//	truthy on successful completion, or falsey if MaxRetries of the underlying trie was exceeded
+func (cache *CMapCache[T]) Delete(key []byte) bool {
+	return cache.CMap.Delete(key)
+}
+
+// Len 
+//	The total key-value pairs in the cache.
+//
+// Returns:
+//	The total key-value pairs
+func (cache *CMapCache[T]) Len() int {
+	return cache.CMap.Len()
+}
+
+// Stats 
+//	Returns the hit, miss and eviction counters of the cache, along with its current size.
+//
+// Returns:
+//	The cache statistics
+func (cache *CMapCache[T]) Stats() CMapCacheStats {
+	root := cache.CMap.loadRoot()
+	return CMapCacheStats{
+		Hits: cache.hits.Load(),
+		Misses: cache.misses.Load(),
+		Evictions: cache.evictions.Load(),
+		Entries: int(root.size),
+		Bytes: root.bytes,
+	}
+}
+
+// overCapacity 
+//	Checks the entry count and byte size on the current root against the capacity of the cache.
+//
+// Returns:
+//	truthy if either bound is exceeded
+func (cache *CMapCache[T]) overCapacity() bool {
+	return cache.exceeds(cache.CMap.loadRoot())
+}
+
+// exceeds 
+//	Checks the entry count and byte size on a root against the capacity of the cache.
+//
+// Parameters:
+//	root: the root to check
+//
+// Returns:
+//	truthy if either bound is exceeded
+func (cache *CMapCache[T]) exceeds(root *cMapRoot[T]) bool {
+	if cache.MaxEntries > 0 && root.size > int64(cache.MaxEntries) { return true }
+	if cache.MaxBytes > 0 && root.bytes > cache.MaxBytes { return true }
+
+	return false
+}
+
+// evict 
+//	Samples leaves from the current root and evicts the least recently used of the sample. 
+//	The leaf is deleted through the normal compare and swap delete path, and only if it is still the same leaf, so a concurrent Put on the key is not lost. 
+//	The capacity is checked again against the root each delete attempt would replace, so concurrent evictions stop as soon as the cache is back within capacity instead of each evicting a key.
+//	If another goroutine changes the key first, nothing is evicted and the caller samples again.
+//
+// Returns:
+//	falsey if the cache is empty, otherwise truthy
+func (cache *CMapCache[T]) evict() bool {
+	root := &cache.CMap.loadRoot().CMapNode
+
+	sampleSize := cache.SampleSize
+	if sampleSize <= 0 { sampleSize = defaultSampleSize }
+
+	var victim CMapLeafNode
+	var victimAccessed int64
+	for idx := 0; idx < sampleSize; idx++ {
+		leaf := cache.CMap.sampleLeaf(root)
+		if leaf == nil { return false }
+
+		accessed := int64(0)
+		if cacheLeaf, ok := leaf.(*cMapCacheLeaf); ok { accessed = cacheLeaf.accessed.Load() }
+		if victim == nil || accessed < victimAccessed { victim, victimAccessed = leaf, accessed }
+	}
+
+	change, err := cache.CMap.updateIf(context.Background(), DeleteOp, victim.Key(), cache.exceeds, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		return nil, existing == victim
+	})
+
+	if err != nil || ! change.applied { return true }
+
+	cache.evictions.Add(1)
+	if cache.OnEvict != nil { cache.OnEvict(victim.Key(), victim.Value()) }
+
+	return true
+}
+
+// sampleLeaf 
+//	Selects a leaf by walking down from a node, choosing a random child on each level.
+//
+// Parameters:
+//	node: the internal node to start the walk from
+//
+// Returns:
+//	The sampled leaf, or nil if the node is empty
+func (cMap *CMap[T]) sampleLeaf(node *CMapNode[T]) CMapLeafNode {
+	for {
+		if len(node.Children) == 0 { return nil }
+
+		switch childNode := node.Children[rand.Intn(len(node.Children))].(type) {
+			case *CMapNode[T]:
+				node = childNode
+			case *CMapCollision:
+				return childNode.Leaves[rand.Intn(len(childNode.Leaves))]
+			case CMapLeafNode:
+				return childNode
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package cmap
+
+import "bufio"
+import "encoding/binary"
+import "errors"
+import "io"
+import "os"
+
+
+//========================================= CMap Change Log
+
+
+// defaultChangeLogCapacity is the number of changes kept in memory when Capacity is not set
+const defaultChangeLogCapacity = 4096
+
+// ErrChangeLogEnabled is returned when enabling a change log on a trie that already has one
+var ErrChangeLogEnabled = errors.New("cmap: change log already enabled")
+
+// ErrChangeLogNotEnabled is returned when reading changes from a trie without a change log
+var ErrChangeLogNotEnabled = errors.New("cmap: change log not enabled")
+
+// ErrChangesTruncated is returned when the changes after a sequence are no longer, or were never, in the change log
+var ErrChangesTruncated = errors.New("cmap: changes since sequence are not in the change log")
+
+// errMalformedRecord is returned when a record in a change log file cannot be decoded
+var errMalformedRecord = errors.New("cmap: malformed change log record")
+
+
+// EnableChangeLog 
+//	Starts logging every change to the trie with its sequence, so changes can be replayed with ChangesSince. 
+//	The log contains every change published after the sequence returned by Seq once EnableChangeLog returns.
+//
+// Parameters:
+//	options: the in memory capacity and optional file of the log
+//
+// Returns:
+//	nil on success, ErrChangeLogEnabled if the trie already has a change log, or the error opening the file
+func (cMap *CMap[T]) EnableChangeLog(options CMapChangeLogOptions) error {
+	capacity := options.Capacity
+	if capacity <= 0 { capacity = defaultChangeLogCapacity }
+
+	changeLog := &cMapChangeLog{ ring: make([]CMapEvent, capacity), sync: options.Sync }
+	changeLog.mu.Lock()
+	defer changeLog.mu.Unlock()
+
+	if ! cMap.changeLog.CompareAndSwap(nil, changeLog) { return ErrChangeLogEnabled }
+
+	if options.Path != "" {
+		file, openErr := os.OpenFile(options.Path, os.O_CREATE | os.O_RDWR | os.O_TRUNC, 0644)
+		if openErr != nil {
+			cMap.changeLog.Store(nil)
+			return openErr
+		}
+
+		changeLog.file = file
+	}
+
+	feed := cMap.changeFeed()
+	subscriber := &cMapSubscriber{ deliver: changeLog.append }
+	feed.addSubscriber(subscriber)
+
+	changeLog.remove = func() { feed.removeSubscriber(subscriber) }
+	changeLog.startSeq = cMap.Seq()
+	changeLog.dropped = changeLog.startSeq
+
+	return nil
+}
+
+// DisableChangeLog 
+//	Stops logging changes and closes the file of the log, if any.
+//
+// Returns:
+//	The first error writing to or closing the file, or ErrChangeLogNotEnabled if the trie has no change log
+func (cMap *CMap[T]) DisableChangeLog() error {
+	changeLog := cMap.changeLog.Swap(nil)
+	if changeLog == nil { return ErrChangeLogNotEnabled }
+
+	changeLog.remove()
+
+	changeLog.mu.Lock()
+	defer changeLog.mu.Unlock()
+
+	if changeLog.file != nil {
+		closeErr := changeLog.file.Close()
+		if changeLog.err == nil { changeLog.err = closeErr }
+	}
+
+	return changeLog.err
+}
+
+// Seq 
+//	The sequence of the current root, which is incremented on each successful mutation.
+//
+// Returns:
+//	The current sequence
+func (cMap *CMap[T]) Seq() uint64 {
+	return cMap.loadRoot().seq
+}
+
+// ChangesSince 
+//	Returns every change with a sequence after the given sequence, in sequence order. 
+//	Changes are read from the in memory ring if it still holds them, otherwise from the file of the log.
+//
+// Parameters:
+//	seq: the sequence of the last change already applied by the caller
+//
+// Returns:
+//	The changes after the sequence, and nil, ErrChangeLogNotEnabled, ErrChangesTruncated if the changes are not in the log, or the error reading the file
+func (cMap *CMap[T]) ChangesSince(seq uint64) ([]CMapEvent, error) {
+	changeLog := cMap.changeLog.Load()
+	if changeLog == nil { return nil, ErrChangeLogNotEnabled }
+
+	return changeLog.since(seq)
+}
+
+// append 
+//	Adds a change to the log, overwriting the oldest change in the ring once it is full. 
+//	Changes are delivered by the change feed in sequence order, one at a time.
+//
+// Parameters:
+//	event: the change to add
+func (changeLog *cMapChangeLog) append(event CMapEvent) {
+	changeLog.mu.Lock()
+	defer changeLog.mu.Unlock()
+
+	if event.Seq <= changeLog.startSeq { return }
+
+	if changeLog.count < len(changeLog.ring) {
+		changeLog.ring[(changeLog.head + changeLog.count) % len(changeLog.ring)] = event
+		changeLog.count++
+	} else {
+		changeLog.dropped = changeLog.ring[changeLog.head].Seq
+		changeLog.ring[changeLog.head] = event
+		changeLog.head = (changeLog.head + 1) % len(changeLog.ring)
+	}
+
+	if changeLog.file == nil || changeLog.err != nil { return }
+
+	record := encodeEvent(event)
+	_, writeErr := changeLog.file.WriteAt(record, changeLog.fileSize)
+	if writeErr == nil && changeLog.sync { writeErr = changeLog.file.Sync() }
+	if writeErr != nil {
+		changeLog.err = writeErr
+		return
+	}
+
+	changeLog.fileSize += int64(len(record))
+}
+
+// since 
+//	Returns the changes in the log with a sequence after the given sequence.
+//	The ring is used if it has not dropped a change after the sequence. Sequences are not contiguous, since some roots bump the sequence without a change, so this is tracked by the newest dropped change rather than the oldest held one.
+//
+// Parameters:
+//	seq: the sequence of the last change already applied by the caller
+//
+// Returns:
+//	The changes after the sequence, and nil, ErrChangesTruncated, or the error reading the file
+func (changeLog *cMapChangeLog) since(seq uint64) ([]CMapEvent, error) {
+	changeLog.mu.RLock()
+
+	if seq < changeLog.startSeq {
+		changeLog.mu.RUnlock()
+		return nil, ErrChangesTruncated
+	}
+
+	if seq >= changeLog.dropped {
+		defer changeLog.mu.RUnlock()
+
+		events := []CMapEvent{}
+		for idx := 0; idx < changeLog.count; idx++ {
+			event := changeLog.ring[(changeLog.head + idx) % len(changeLog.ring)]
+			if event.Seq > seq { events = append(events, event) }
+		}
+
+		return events, nil
+	}
+
+	file, fileSize, err := changeLog.file, changeLog.fileSize, changeLog.err
+	changeLog.mu.RUnlock()
+
+	if file == nil { return nil, ErrChangesTruncated }
+	if err != nil { return nil, err }
+
+	return readEvents(io.NewSectionReader(file, 0, fileSize), seq)
+}
+
+// encodeEvent 
+//	Encodes a change as a length prefixed record. 
+//	Values are written with their length plus one, so a missing value is distinguished from an empty one.
+//
+// Parameters:
+//	event: the change to encode
+//
+// Returns:
+//	The record
+func encodeEvent(event CMapEvent) []byte {
+	record := make([]byte, 4, 4 + 9 + 3 * binary.MaxVarintLen64 + len(event.Key) + len(event.OldValue) + len(event.NewValue))
+	record = binary.LittleEndian.AppendUint64(record, event.Seq)
+	record = append(record, byte(event.Type))
+
+	record = binary.AppendUvarint(record, uint64(len(event.Key)))
+	record = append(record, event.Key...)
+
+	for _, value := range [][]byte{ event.OldValue, event.NewValue } {
+		if value == nil {
+			record = binary.AppendUvarint(record, 0)
+		} else {
+			record = binary.AppendUvarint(record, uint64(len(value)) + 1)
+			record = append(record, value...)
+		}
+	}
+
+	binary.LittleEndian.PutUint32(record, uint32(len(record) - 4))
+	return record
+}
+
+// readEvents 
+//	Decodes the records of a change log file, keeping the changes after a sequence.
+//
+// Parameters:
+//	reader: the records
+//	seq: changes with this sequence or before are skipped
+//
+// Returns:
+//	The changes after the sequence, and the error reading the records
+func readEvents(reader io.Reader, seq uint64) ([]CMapEvent, error) {
+	bufReader := bufio.NewReader(reader)
+	events := []CMapEvent{}
+
+	var lengthBuf [4]byte
+	for {
+		if _, readErr := io.ReadFull(bufReader, lengthBuf[:]); readErr != nil {
+			if readErr == io.EOF { return events, nil }
+			return nil, readErr
+		}
+
+		record := make([]byte, binary.LittleEndian.Uint32(lengthBuf[:]))
+		if _, readErr := io.ReadFull(bufReader, record); readErr != nil { return nil, readErr }
+
+		event, decodeErr := decodeEvent(record)
+		if decodeErr != nil { return nil, decodeErr }
+		if event.Seq > seq { events = append(events, event) }
+	}
+}
+
+// decodeEvent 
+//	Decodes a change from a record, without its length prefix.
+//
+// Parameters:
+//	record: the encoded change
+//
+// Returns:
+//	The change, and an error if the record is malformed
+func decodeEvent(record []byte) (CMapEvent, error) {
+	if len(record) < 9 { return CMapEvent{}, errMalformedRecord }
+
+	event := CMapEvent{ Seq: binary.LittleEndian.Uint64(record), Type: CMapEventType(record[8]) }
+	record = record[9:]
+
+	keyLen, n := binary.Uvarint(record)
+	if n <= 0 || uint64(len(record) - n) < keyLen { return CMapEvent{}, errMalformedRecord }
+
+	event.Key = record[n:n + int(keyLen)]
+	record = record[n + int(keyLen):]
+
+	values := [2][]byte{}
+	for idx := range values {
+		valueLen, n := binary.Uvarint(record)
+		if n <= 0 || uint64(len(record) - n) + 1 < valueLen { return CMapEvent{}, errMalformedRecord }
+
+		record = record[n:]
+		if valueLen == 0 { continue }
+
+		values[idx] = record[:valueLen - 1]
+		record = record[valueLen - 1:]
+	}
+
+	event.OldValue, event.NewValue = values[0], values[1]
+	return event, nil
+}
@@ -0,0 +1,97 @@
+package cmap
+
+import "bytes"
+import "context"
+
+
+//========================================= CMap Conditional
+
+
+// PutWithOptions 
+//	Inserts or updates a key-value pair if the condition on the existing key holds, optionally with a time to live. 
+//	The condition is checked against the leaf the compare and swap replaces, so it holds at the point in time of the write.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//	options: the condition and time to live of the put
+//
+// Returns:
+//	truthy if the key-value pair was written, and nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (cMap *CMap[T]) PutWithOptions(ctx context.Context, key []byte, value []byte, options CMapPutOptions) (bool, error) {
+	var expiresAt int64
+	if options.TTL > 0 { expiresAt = cMap.expiresAt(options.TTL) }
+
+	change, err := cMap.update(ctx, PutOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		present := existing != nil && ! cMap.isExpired(existing, cMap.now())
+		if options.Condition == IfAbsent && present { return nil, false }
+		if options.Condition == IfPresent && ! present { return nil, false }
+		if options.Match != nil && (! present || ! options.Match(existing.Value())) { return nil, false }
+
+		if expiresAt > 0 { return &cMapExpiringLeaf{ key: key, value: value, expiresAt: expiresAt }, true }
+		return cMap.NewLeafNode(key, value), true
+	})
+
+	if err != nil { return false, err }
+	return change.applied, nil
+}
+
+// LoadAndDelete 
+//	Deletes a key-value pair, returning the value it held. Expired keys are deleted but treated as absent.
+//
+// Parameters:
+//	key: the key to delete
+//
+// Returns:
+//	The deleted value, and truthy if the key was present
+func (cMap *CMap[T]) LoadAndDelete(key []byte) ([]byte, bool) {
+	change, err := cMap.update(context.Background(), DeleteOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		return nil, existing != nil
+	})
+
+	if err != nil || ! change.applied || cMap.isExpired(change.existing, cMap.now()) { return nil, false }
+	return change.existing.Value(), true
+}
+
+// CompareAndSwap 
+//	Replaces the value for a key only if the key currently holds the old value. Expired keys are treated as absent. 
+//	The comparison is against the leaf the compare and swap replaces, so no write between reading the old value and the swap is lost.
+//
+// Parameters:
+//	key: the key to update
+//	old: the value the key must hold
+//	new: the value to replace it with
+//
+// Returns:
+//	truthy if the value was swapped
+func (cMap *CMap[T]) CompareAndSwap(key []byte, old []byte, new []byte) bool {
+	change, err := cMap.update(context.Background(), PutOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		if ! cMap.holdsValue(existing, old) { return nil, false }
+		return cMap.NewLeafNode(key, new), true
+	})
+
+	return err == nil && change.applied
+}
+
+// CompareAndDelete 
+//	Deletes a key only if it currently holds the old value. Expired keys are treated as absent.
+//
+// Parameters:
+//	key: the key to delete
+//	old: the value the key must hold
+//
+// Returns:
+//	truthy if the key was deleted
+func (cMap *CMap[T]) CompareAndDelete(key []byte, old []byte) bool {
+	change, err := cMap.update(context.Background(), DeleteOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		return nil, cMap.holdsValue(existing, old)
+	})
+
+	return err == nil && change.applied
+}
+
+// holdsValue returns whether a leaf is present, not expired, and holds the value
+func (cMap *CMap[T]) holdsValue(leaf CMapLeafNode, value []byte) bool {
+	return leaf != nil && ! cMap.isExpired(leaf, cMap.now()) && bytes.Equal(leaf.Value(), value)
+}
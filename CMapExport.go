@@ -0,0 +1,161 @@
+package cmap
+
+import "bufio"
+import "encoding/hex"
+import "encoding/json"
+import "fmt"
+import "io"
+import "strconv"
+import "strings"
+import "unicode/utf8"
+
+
+//========================================= CMap Export
+
+
+// exportKeyLength is the number of characters of a key shown before it is abbreviated
+const exportKeyLength = 16
+
+
+// ExportDOT 
+//	Renders the current version of the trie as a Graphviz DOT graph. 
+//	Internal nodes show their level, reseed count and bitmap in binary, edges are labelled with sparse indexes, and leaves show abbreviated keys.
+//
+// Parameters:
+//	w: the writer for the graph
+//	maxDepth: the deepest level to render. Subtrees below it are collapsed into a node with their leaf count. If 0, the whole trie is rendered
+//
+// Returns:
+//	The error writing the graph
+func (cMap *CMap[T]) ExportDOT(w io.Writer, maxDepth int) error {
+	root := cMap.exportNode(&cMap.loadRoot().CMapNode, 0, maxDepth)
+
+	bufWriter := bufio.NewWriter(w)
+	bufWriter.WriteString("digraph cmap {\n\tnode [fontname=\"monospace\"];\n")
+
+	nextId := 0
+	var writeNode func(node *cMapExportNode) string
+	writeNode = func(node *cMapExportNode) string {
+		id := "n" + strconv.Itoa(nextId)
+		nextId++
+
+		switch node.Type {
+			case "internal":
+				label := fmt.Sprintf("level %d", node.Level)
+				if node.Seed > 0 { label += fmt.Sprintf(", seed %d", node.Seed) }
+
+				fmt.Fprintf(bufWriter, "\t%s [shape=record, label=%s];\n", id, strconv.Quote(label + "|" + node.Bitmap))
+				for _, edge := range node.Children {
+					childId := writeNode(edge.Node)
+					fmt.Fprintf(bufWriter, "\t%s -> %s [label=\"%d\"];\n", id, childId, edge.Index)
+				}
+			case "truncated":
+				fmt.Fprintf(bufWriter, "\t%s [shape=plaintext, label=%s];\n", id, strconv.Quote(fmt.Sprintf("... %d leaves", node.Leaves)))
+			case "collision":
+				fmt.Fprintf(bufWriter, "\t%s [shape=box, style=dashed, label=%s];\n", id, strconv.Quote(strings.Join(node.Keys, "\n")))
+			default:
+				fmt.Fprintf(bufWriter, "\t%s [shape=box, label=%s];\n", id, strconv.Quote(node.Keys[0]))
+		}
+
+		return id
+	}
+
+	writeNode(root)
+	bufWriter.WriteString("}\n")
+
+	return bufWriter.Flush()
+}
+
+// ExportJSON 
+//	Renders the current version of the trie as nested JSON objects, with the same information as ExportDOT.
+//
+// Parameters:
+//	w: the writer for the JSON
+//	maxDepth: the deepest level to render. Subtrees below it are collapsed into a node with their leaf count. If 0, the whole trie is rendered
+//
+// Returns:
+//	The error writing the JSON
+func (cMap *CMap[T]) ExportJSON(w io.Writer, maxDepth int) error {
+	return json.NewEncoder(w).Encode(cMap.exportNode(&cMap.loadRoot().CMapNode, 0, maxDepth))
+}
+
+// exportNode 
+//	Builds the rendered form of an internal node and the nodes below it.
+//
+// Parameters:
+//	node: the internal node
+//	level: the level of the node
+//	maxDepth: the deepest level to render, or 0 for no limit
+//
+// Returns:
+//	The rendered node
+func (cMap *CMap[T]) exportNode(node *CMapNode[T], level int, maxDepth int) *cMapExportNode {
+	if maxDepth > 0 && level >= maxDepth {
+		leaves := 0
+		cMap.walkLeaves(node, func(CMapLeafNode) bool {
+			leaves++
+			return true
+		})
+
+		return &cMapExportNode{ Type: "truncated", Level: level, Leaves: leaves }
+	}
+
+	exported := &cMapExportNode{
+		Type: "internal",
+		Level: level,
+		Bitmap: fmt.Sprintf("%0*b", 1 << cMap.BitChunkSize, node.Bitmap),
+		Seed: level / cMap.HashChunks,
+	}
+
+	pos := 0
+	for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+		if ! IsBitSet(node.Bitmap, index) { continue }
+
+		var child *cMapExportNode
+		switch childNode := node.Children[pos].(type) {
+			case *CMapNode[T]:
+				child = cMap.exportNode(childNode, level + 1, maxDepth)
+			case *CMapCollision:
+				child = &cMapExportNode{ Type: "collision", Level: level + 1 }
+				for _, leaf := range childNode.Leaves { child.Keys = append(child.Keys, abbreviateKey(leaf.Key())) }
+			case CMapLeafNode:
+				child = &cMapExportNode{ 
+					Type: "leaf", 
+					Level: level + 1, 
+					Keys: []string{ abbreviateKey(childNode.Key()) }, 
+					KeyBytes: len(childNode.Key()), 
+					ValueBytes: len(childNode.Value()),
+				}
+		}
+
+		exported.Children = append(exported.Children, cMapExportEdge{ Index: index, Node: child })
+		pos++
+	}
+
+	return exported
+}
+
+// abbreviateKey 
+//	Shortens a key for display. Printable keys are shown as text, and other keys as hex.
+//
+// Parameters:
+//	key: the key to abbreviate
+//
+// Returns:
+//	The abbreviated key, ending in ... if it was shortened
+func abbreviateKey(key []byte) string {
+	printable := utf8.Valid(key)
+	for _, r := range string(key) {
+		if r < 0x20 || r == 0x7f { printable = false }
+	}
+
+	if ! printable {
+		if len(key) > exportKeyLength / 2 { return "0x" + hex.EncodeToString(key[:exportKeyLength / 2]) + "..." }
+		return "0x" + hex.EncodeToString(key)
+	}
+
+	runes := []rune(string(key))
+	if len(runes) > exportKeyLength { return string(runes[:exportKeyLength]) + "..." }
+
+	return string(key)
+}
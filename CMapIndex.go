@@ -0,0 +1,356 @@
+package cmap
+
+import "bytes"
+import "errors"
+import "sync/atomic"
+import "unsafe"
+
+
+//========================================= CMap Ordered Index
+
+
+// indexPrioritySeed is the seed of the hash used for the priorities of the ordered index
+const indexPrioritySeed = 0
+
+// ErrNotIndexed is returned by ordered queries on a map without an ordered index
+var ErrNotIndexed = errors.New("cmap: ordered index not enabled")
+
+
+// EnableOrderedIndex 
+//	Enables the ordered index, which keeps the keys of the trie in sorted order for range and prefix queries.
+//	The index is kept on the root, so every later mutation updates it with the same compare and swap as the trie, and every version and view of the map has an index consistent with its trie.
+//	The index is built from the current leaves and published as a new version with the next sequence, so the version retained for it is indexed as well. No change is published to the change feed. 
+//	Enabling an index that is already enabled does nothing.
+func (cMap *CMap[T]) EnableOrderedIndex() {
+	for {
+		currRoot := cMap.loadRoot()
+		if currRoot.indexed { return }
+
+		var index *cMapIndexNode
+		cMap.walkLeaves(&currRoot.CMapNode, func(leaf CMapLeafNode) bool {
+			index = index.insert(leaf)
+			return true
+		})
+
+		newRoot := &cMapRoot[T]{
+			CMapNode: CMapNode[T]{ Bitmap: currRoot.Bitmap, Children: currRoot.Children },
+			size: currRoot.size,
+			bytes: currRoot.bytes,
+			seq: currRoot.seq + 1,
+			feed: currRoot.feed,
+			indexed: true,
+			index: index,
+		}
+
+		if atomic.CompareAndSwapPointer(&cMap.Root, unsafe.Pointer(currRoot), unsafe.Pointer(newRoot)) {
+			cMap.retainVersion(newRoot)
+			if newRoot.feed != nil { newRoot.feed.advance(newRoot.seq) }
+
+			return
+		}
+	}
+}
+
+// RangeScan 
+//	Visits the key-value pairs with keys from start up to but not including end, in ascending order, as of the current version. See CMapView.RangeScan.
+//
+// Parameters:
+//	start: the first key in the range, or nil for no lower bound
+//	end: the key after the range, or nil for no upper bound
+//	fn: called for each key-value pair. Returning falsey stops the scan
+//
+// Returns:
+//	ErrNotIndexed if the ordered index is not enabled
+func (cMap *CMap[T]) RangeScan(start []byte, end []byte, fn func(key []byte, value []byte) bool) error {
+	return cMap.View().RangeScan(start, end, fn)
+}
+
+// ReverseRangeScan 
+//	Same as RangeScan, in descending order.
+func (cMap *CMap[T]) ReverseRangeScan(start []byte, end []byte, fn func(key []byte, value []byte) bool) error {
+	return cMap.View().ReverseRangeScan(start, end, fn)
+}
+
+// PrefixScan 
+//	Visits the key-value pairs with keys starting with a prefix, in ascending order, as of the current version.
+//
+// Parameters:
+//	prefix: the prefix of the keys
+//	fn: called for each key-value pair. Returning falsey stops the scan
+//
+// Returns:
+//	ErrNotIndexed if the ordered index is not enabled
+func (cMap *CMap[T]) PrefixScan(prefix []byte, fn func(key []byte, value []byte) bool) error {
+	return cMap.View().PrefixScan(prefix, fn)
+}
+
+// Min 
+//	Gets the key-value pair with the smallest key, as of the current version.
+//
+// Returns:
+//	The key and value, nil if the map is empty, and ErrNotIndexed if the ordered index is not enabled
+func (cMap *CMap[T]) Min() ([]byte, []byte, error) {
+	return cMap.View().Min()
+}
+
+// Max 
+//	Gets the key-value pair with the largest key, as of the current version.
+//
+// Returns:
+//	The key and value, nil if the map is empty, and ErrNotIndexed if the ordered index is not enabled
+func (cMap *CMap[T]) Max() ([]byte, []byte, error) {
+	return cMap.View().Max()
+}
+
+// RangeScan 
+//	Visits the key-value pairs with keys from start up to but not including end, in ascending order, as of the version of the view.
+//	Keys are compared byte by byte, and expired keys are skipped.
+//
+// Parameters:
+//	start: the first key in the range, or nil for no lower bound
+//	end: the key after the range, or nil for no upper bound
+//	fn: called for each key-value pair. Returning falsey stops the scan
+//
+// Returns:
+//	ErrNotIndexed if the ordered index is not enabled
+func (view *CMapView[T]) RangeScan(start []byte, end []byte, fn func(key []byte, value []byte) bool) error {
+	if ! view.root.indexed { return ErrNotIndexed }
+
+	view.root.index.ascend(start, end, view.visitUnexpired(fn))
+	return nil
+}
+
+// ReverseRangeScan 
+//	Same as RangeScan, in descending order.
+func (view *CMapView[T]) ReverseRangeScan(start []byte, end []byte, fn func(key []byte, value []byte) bool) error {
+	if ! view.root.indexed { return ErrNotIndexed }
+
+	view.root.index.descend(start, end, view.visitUnexpired(fn))
+	return nil
+}
+
+// PrefixScan 
+//	Visits the key-value pairs with keys starting with a prefix, in ascending order, as of the version of the view.
+//
+// Parameters:
+//	prefix: the prefix of the keys
+//	fn: called for each key-value pair. Returning falsey stops the scan
+//
+// Returns:
+//	ErrNotIndexed if the ordered index is not enabled
+func (view *CMapView[T]) PrefixScan(prefix []byte, fn func(key []byte, value []byte) bool) error {
+	return view.RangeScan(prefix, prefixEnd(prefix), fn)
+}
+
+// Min 
+//	Gets the key-value pair with the smallest unexpired key, as of the version of the view.
+//
+// Returns:
+//	The key and value, nil if there are no keys, and ErrNotIndexed if the ordered index is not enabled
+func (view *CMapView[T]) Min() ([]byte, []byte, error) {
+	var key, value []byte
+	err := view.RangeScan(nil, nil, func(minKey []byte, minValue []byte) bool {
+		key, value = minKey, minValue
+		return false
+	})
+
+	return key, value, err
+}
+
+// Max 
+//	Gets the key-value pair with the largest unexpired key, as of the version of the view.
+//
+// Returns:
+//	The key and value, nil if there are no keys, and ErrNotIndexed if the ordered index is not enabled
+func (view *CMapView[T]) Max() ([]byte, []byte, error) {
+	var key, value []byte
+	err := view.ReverseRangeScan(nil, nil, func(maxKey []byte, maxValue []byte) bool {
+		key, value = maxKey, maxValue
+		return false
+	})
+
+	return key, value, err
+}
+
+// visitUnexpired wraps a scan function to skip expired leaves
+func (view *CMapView[T]) visitUnexpired(fn func(key []byte, value []byte) bool) func(leaf CMapLeafNode) bool {
+	now := view.cMap.now()
+	return func(leaf CMapLeafNode) bool {
+		if view.cMap.isExpired(leaf, now) { return true }
+		return fn(leaf.Key(), leaf.Value())
+	}
+}
+
+// nextIndex 
+//	Applies a change to a leaf to the ordered index of a root.
+//
+// Parameters:
+//	change: the change made to the leaf for a key
+//
+// Returns:
+//	The root of the new ordered index, sharing every node off the path to the key, or the current index if the root is not indexed
+func (root *cMapRoot[T]) nextIndex(change cMapChange) *cMapIndexNode {
+	if ! root.indexed || ! change.applied { return root.index }
+	if change.leaf == nil { return root.index.delete(change.existing.Key()) }
+
+	return root.index.insert(change.leaf)
+}
+
+// insert 
+//	Inserts or replaces a leaf in the treap below a node, copying the path to the key.
+//	Every node returned by insert is a new node, so rotations on the way back up modify only the copies.
+//
+// Parameters:
+//	leaf: the leaf to insert
+//
+// Returns:
+//	The new root of the subtree
+func (node *cMapIndexNode) insert(leaf CMapLeafNode) *cMapIndexNode {
+	if node == nil { return &cMapIndexNode{ leaf: leaf, priority: Murmur32(leaf.Key(), indexPrioritySeed) } }
+
+	nodeCopy := *node
+	switch cmp := bytes.Compare(leaf.Key(), node.leaf.Key()); {
+		case cmp == 0:
+			nodeCopy.leaf = leaf
+		case cmp < 0:
+			left := node.left.insert(leaf)
+			nodeCopy.left = left
+			if left.priority > nodeCopy.priority {
+				nodeCopy.left = left.right
+				left.right = &nodeCopy
+				return left
+			}
+		default:
+			right := node.right.insert(leaf)
+			nodeCopy.right = right
+			if right.priority > nodeCopy.priority {
+				nodeCopy.right = right.left
+				right.left = &nodeCopy
+				return right
+			}
+	}
+
+	return &nodeCopy
+}
+
+// delete 
+//	Removes a key from the treap below a node, copying the path to the key.
+//
+// Parameters:
+//	key: the key to remove
+//
+// Returns:
+//	The new root of the subtree, or the node itself if the key is not present
+func (node *cMapIndexNode) delete(key []byte) *cMapIndexNode {
+	if node == nil { return nil }
+
+	switch cmp := bytes.Compare(key, node.leaf.Key()); {
+		case cmp == 0:
+			return mergeIndex(node.left, node.right)
+		case cmp < 0:
+			left := node.left.delete(key)
+			if left == node.left { return node }
+
+			nodeCopy := *node
+			nodeCopy.left = left
+			return &nodeCopy
+		default:
+			right := node.right.delete(key)
+			if right == node.right { return node }
+
+			nodeCopy := *node
+			nodeCopy.right = right
+			return &nodeCopy
+	}
+}
+
+// mergeIndex 
+//	Joins two treaps where every key in the first is smaller than every key in the second, copying the nodes along the seam.
+//
+// Parameters:
+//	left: the treap with the smaller keys
+//	right: the treap with the larger keys
+//
+// Returns:
+//	The root of the joined treap
+func mergeIndex(left *cMapIndexNode, right *cMapIndexNode) *cMapIndexNode {
+	if left == nil { return right }
+	if right == nil { return left }
+
+	if left.priority > right.priority {
+		nodeCopy := *left
+		nodeCopy.right = mergeIndex(left.right, right)
+		return &nodeCopy
+	}
+
+	nodeCopy := *right
+	nodeCopy.left = mergeIndex(left, right.left)
+	return &nodeCopy
+}
+
+// ascend 
+//	Visits the leaves below a node with keys from start up to but not including end, in ascending order.
+//
+// Parameters:
+//	start: the first key, or nil for no lower bound
+//	end: the key after the range, or nil for no upper bound
+//	visit: called for each leaf. Returning falsey stops the walk
+//
+// Returns:
+//	falsey if the walk was stopped
+func (node *cMapIndexNode) ascend(start []byte, end []byte, visit func(leaf CMapLeafNode) bool) bool {
+	if node == nil { return true }
+
+	afterStart := start == nil || bytes.Compare(node.leaf.Key(), start) >= 0
+	beforeEnd := end == nil || bytes.Compare(node.leaf.Key(), end) < 0
+
+	if afterStart && ! node.left.ascend(start, end, visit) { return false }
+	if afterStart && beforeEnd && ! visit(node.leaf) { return false }
+	if beforeEnd { return node.right.ascend(start, end, visit) }
+
+	return true
+}
+
+// descend 
+//	Visits the leaves below a node with keys from start up to but not including end, in descending order.
+//
+// Parameters:
+//	start: the first key, or nil for no lower bound
+//	end: the key after the range, or nil for no upper bound
+//	visit: called for each leaf. Returning falsey stops the walk
+//
+// Returns:
+//	falsey if the walk was stopped
+func (node *cMapIndexNode) descend(start []byte, end []byte, visit func(leaf CMapLeafNode) bool) bool {
+	if node == nil { return true }
+
+	afterStart := start == nil || bytes.Compare(node.leaf.Key(), start) >= 0
+	beforeEnd := end == nil || bytes.Compare(node.leaf.Key(), end) < 0
+
+	if beforeEnd && ! node.right.descend(start, end, visit) { return false }
+	if afterStart && beforeEnd && ! visit(node.leaf) { return false }
+	if afterStart { return node.left.descend(start, end, visit) }
+
+	return true
+}
+
+// prefixEnd 
+//	Gets the smallest key greater than every key starting with a prefix, by incrementing the last byte of the prefix that is not 0xff.
+//
+// Parameters:
+//	prefix: the prefix
+//
+// Returns:
+//	The key after the prefix, or nil if there is none
+func prefixEnd(prefix []byte) []byte {
+	for idx := len(prefix) - 1; idx >= 0; idx-- {
+		if prefix[idx] < 0xff {
+			end := append([]byte(nil), prefix[:idx + 1]...)
+			end[idx]++
+
+			return end
+		}
+	}
+
+	return nil
+}
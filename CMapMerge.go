@@ -0,0 +1,485 @@
+package cmap
+
+import "bytes"
+
+
+//========================================= CMap Merge
+
+
+// unionNodes 
+//	Merges two children in the same slot of two tries into a child holding the leaves of both.
+//	Identical children are reused as is.
+//	If both are internal nodes, their children are merged index by index, reusing the children present on only one side, and the first node is reused if nothing was added to it.
+//	Otherwise the leaves of the leaf or collision node are inserted into the other child. Where both hold the same key, the leaf of the first child is kept when it is an internal node.
+//	Only the leaves added to the first child are tallied, so subtrees shared by both are never walked.
+//
+// Parameters:
+//	a: the child from the first trie
+//	b: the child from the second trie
+//	level: the level of the children within the trie
+//
+// Returns:
+//	The merged child, and the tally of the merged child less the tally of the first child
+func (cMap *CMap[T]) unionNodes(a CMapChildNode, b CMapChildNode, level int) (CMapChildNode, cMapTally) {
+	if a == b { return a, cMapTally{} }
+
+	aNode, aInternal := a.(*CMapNode[T])
+	bNode, bInternal := b.(*CMapNode[T])
+
+	if aInternal && bInternal {
+		merged := cMap.allocNode(calculateHammingWeight(aNode.Bitmap | bNode.Bitmap))
+		merged.Bitmap = aNode.Bitmap | bNode.Bitmap
+
+		var added cMapTally
+		changed := merged.Bitmap != aNode.Bitmap
+		posA, posB := 0, 0
+
+		for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+			inA, inB := IsBitSet(aNode.Bitmap, index), IsBitSet(bNode.Bitmap, index)
+			pos := posA + posB - cMap.sharedBefore(aNode.Bitmap, bNode.Bitmap, index)
+
+			switch {
+				case inA && inB:
+					child, childAdded := cMap.unionNodes(aNode.Children[posA], bNode.Children[posB], level + 1)
+					added.add(childAdded)
+					if child != aNode.Children[posA] { changed = true }
+
+					merged.Children[pos] = child
+					posA++
+					posB++
+				case inA:
+					merged.Children[pos] = aNode.Children[posA]
+					posA++
+				case inB:
+					merged.Children[pos] = bNode.Children[posB]
+					added.add(cMap.tallyNode(bNode.Children[posB]))
+					posB++
+			}
+		}
+
+		if ! changed { 
+			cMap.releaseNode(merged)
+			return a, added 
+		}
+
+		return merged, added
+	}
+
+	var added cMapTally
+	if ! aInternal && bInternal {
+		added = cMap.tallyNode(b)
+
+		target := b
+		for _, leaf := range leavesOf(a) {
+			var existing CMapLeafNode
+			target, existing = cMap.insertLeaf(target, leaf, level, false)
+			if existing != nil { added.subtractLeaf(leaf) }
+		}
+
+		return target, added
+	}
+
+	target := a
+	for _, leaf := range leavesOf(b) {
+		var existing CMapLeafNode
+		target, existing = cMap.insertLeaf(target, leaf, level, false)
+		if existing == nil { added.addLeaf(leaf) }
+	}
+
+	return target, added
+}
+
+// intersectNodes 
+//	Merges two children in the same slot of two tries into a child holding only the leaves in both.
+//	Identical children are reused as is.
+//	If both are internal nodes, their children are intersected index by index, and the first node is reused if nothing was removed from it.
+//	Otherwise the leaves of the leaf or collision node are kept if the other child holds the same key.
+//
+// Parameters:
+//	a: the child from the first trie
+//	b: the child from the second trie
+//	level: the level of the children within the trie
+//
+// Returns:
+//	The merged child, or nil if no leaves are in both, and the tally of the leaves in the merged child
+func (cMap *CMap[T]) intersectNodes(a CMapChildNode, b CMapChildNode, level int) (CMapChildNode, cMapTally) {
+	if a == b { return a, cMap.tallyNode(a) }
+
+	aNode, aInternal := a.(*CMapNode[T])
+	bNode, bInternal := b.(*CMapNode[T])
+
+	if aInternal && bInternal {
+		var tally cMapTally
+		var bitMap T
+		children := make([]CMapChildNode, 0, calculateHammingWeight(aNode.Bitmap & bNode.Bitmap))
+		changed := aNode.Bitmap & bNode.Bitmap != aNode.Bitmap
+		posA, posB := 0, 0
+
+		for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+			inA, inB := IsBitSet(aNode.Bitmap, index), IsBitSet(bNode.Bitmap, index)
+			if inA && inB {
+				child, childTally := cMap.intersectNodes(aNode.Children[posA], bNode.Children[posB], level + 1)
+				if child != aNode.Children[posA] { changed = true }
+				if child != nil {
+					bitMap = SetBit(bitMap, index)
+					children = append(children, child)
+					tally.add(childTally)
+				}
+			}
+
+			if inA { posA++ }
+			if inB { posB++ }
+		}
+
+		if ! changed { return a, tally }
+		return cMap.nodeFromChildren(bitMap, children), tally
+	}
+
+	source, other := a, b
+	if aInternal { source, other = b, a }
+
+	var tally cMapTally
+	return cMap.filterLeaves(source, func(leaf CMapLeafNode) bool {
+		if ! cMap.containsKey(other, leaf.Key(), level) { return false }
+
+		tally.addLeaf(leaf)
+		return true
+	}), tally
+}
+
+// differenceNodes 
+//	Merges two children in the same slot of two tries into a child holding the leaves of the first that are not in the second.
+//	Identical children cancel out.
+//	If both are internal nodes, the children of the first are reduced index by index, reusing the children with nothing to remove, and the first node is reused if nothing was removed from it.
+//	Otherwise the keys of the leaf or collision node in the second child are removed from the first, or the leaves of the first are kept if the second does not hold the same key.
+//
+// Parameters:
+//	a: the child from the first trie
+//	b: the child from the second trie
+//	level: the level of the children within the trie
+//
+// Returns:
+//	The merged child, or nil if no leaves remain, and the tally of the leaves removed from the first child
+func (cMap *CMap[T]) differenceNodes(a CMapChildNode, b CMapChildNode, level int) (CMapChildNode, cMapTally) {
+	if a == b { return nil, cMap.tallyNode(a) }
+
+	aNode, aInternal := a.(*CMapNode[T])
+	bNode, bInternal := b.(*CMapNode[T])
+
+	var removed cMapTally
+	if aInternal && bInternal {
+		var bitMap T
+		children := make([]CMapChildNode, 0, len(aNode.Children))
+		changed := false
+		posA, posB := 0, 0
+
+		for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+			inA, inB := IsBitSet(aNode.Bitmap, index), IsBitSet(bNode.Bitmap, index)
+			if inA {
+				child := aNode.Children[posA]
+				if inB {
+					var childRemoved cMapTally
+					child, childRemoved = cMap.differenceNodes(child, bNode.Children[posB], level + 1)
+					removed.add(childRemoved)
+					if child != aNode.Children[posA] { changed = true }
+				}
+
+				if child != nil {
+					bitMap = SetBit(bitMap, index)
+					children = append(children, child)
+				}
+			}
+
+			if inA { posA++ }
+			if inB { posB++ }
+		}
+
+		if ! changed { return a, removed }
+		return cMap.nodeFromChildren(bitMap, children), removed
+	}
+
+	if aInternal {
+		var result CMapChildNode = a
+		for _, leaf := range leavesOf(b) {
+			var removedLeaf CMapLeafNode
+			result, removedLeaf = cMap.removeLeaf(result, leaf.Key(), level)
+			if removedLeaf != nil { removed.addLeaf(removedLeaf) }
+			if result == nil { break }
+		}
+
+		return result, removed
+	}
+
+	return cMap.filterLeaves(a, func(leaf CMapLeafNode) bool {
+		if ! cMap.containsKey(b, leaf.Key(), level) { return true }
+
+		removed.addLeaf(leaf)
+		return false
+	}), removed
+}
+
+// insertLeaf 
+//	Inserts a leaf into a child of a trie that is not yet published, path copying from the child down to the slot for the key.
+//	If the child already holds the key, the existing leaf is replaced, or if not replacing, the child is returned unchanged.
+//
+// Parameters:
+//	child: the child to insert into
+//	leaf: the leaf to insert
+//	level: the level of the child within the trie
+//	replace: whether to replace an existing leaf with the same key
+//
+// Returns:
+//	The child with the leaf inserted, and the existing leaf with the same key, or nil if the child did not hold the key
+func (cMap *CMap[T]) insertLeaf(child CMapChildNode, leaf CMapLeafNode, level int, replace bool) (CMapChildNode, CMapLeafNode) {
+	switch node := child.(type) {
+		case *CMapNode[T]:
+			hash := cMap.CalculateHashForCurrentLevel(leaf.Key(), level)
+			index := cMap.getSparseIndex(hash, level)
+
+			if ! IsBitSet(node.Bitmap, index) {
+				bitMap := SetBit(node.Bitmap, index)
+				return cMap.copyNodeWithInsert(node, bitMap, cMap.getPosition(bitMap, hash, level), leaf), nil
+			}
+
+			pos := cMap.getPosition(node.Bitmap, hash, level)
+			newChild, existing := cMap.insertLeaf(node.Children[pos], leaf, level + 1, replace)
+			if existing != nil && ! replace { return node, existing }
+
+			nodeCopy := cMap.CopyNode(node)
+			nodeCopy.Children[pos] = newChild
+
+			return nodeCopy, existing
+		case *CMapCollision:
+			idx := node.find(leaf.Key())
+			if idx == -1 { return node.withLeaf(leaf), nil }
+			if ! replace { return node, node.Leaves[idx] }
+
+			return node.withLeaf(leaf), node.Leaves[idx]
+		case CMapLeafNode:
+			if ! bytes.Equal(node.Key(), leaf.Key()) { return cMap.splitLeaf(node, leaf, level), nil }
+			if ! replace { return node, node }
+
+			return leaf, node
+	}
+
+	return child, nil
+}
+
+// removeLeaf 
+//	Removes the leaf for a key from a child of a trie that is not yet published, path copying from the child down to the slot for the key.
+//	Internal nodes left without children are removed as well.
+//
+// Parameters:
+//	child: the child to remove from
+//	key: the key to remove
+//	level: the level of the child within the trie
+//
+// Returns:
+//	The child with the leaf removed, or nil if nothing remains, and the removed leaf, or nil if the child did not hold the key
+func (cMap *CMap[T]) removeLeaf(child CMapChildNode, key []byte, level int) (CMapChildNode, CMapLeafNode) {
+	switch node := child.(type) {
+		case *CMapNode[T]:
+			hash := cMap.CalculateHashForCurrentLevel(key, level)
+			index := cMap.getSparseIndex(hash, level)
+			if ! IsBitSet(node.Bitmap, index) { return node, nil }
+
+			pos := cMap.getPosition(node.Bitmap, hash, level)
+			newChild, removed := cMap.removeLeaf(node.Children[pos], key, level + 1)
+			if removed == nil { return node, nil }
+
+			if newChild == nil {
+				if len(node.Children) == 1 { return nil, removed }
+				return cMap.copyNodeWithRemove(node, SetBit(node.Bitmap, index), pos), removed
+			}
+
+			nodeCopy := cMap.CopyNode(node)
+			nodeCopy.Children[pos] = newChild
+
+			return nodeCopy, removed
+		case *CMapCollision:
+			idx := node.find(key)
+			if idx == -1 { return node, nil }
+
+			return node.withoutLeaf(idx), node.Leaves[idx]
+		case CMapLeafNode:
+			if bytes.Equal(node.Key(), key) { return nil, node }
+	}
+
+	return child, nil
+}
+
+// containsKey 
+//	Determines whether a child of a trie holds a key.
+//
+// Parameters:
+//	child: the child to search
+//	key: the key to search for
+//	level: the level of the child within the trie
+//
+// Returns:
+//	truthy if the child holds the key
+func (cMap *CMap[T]) containsKey(child CMapChildNode, key []byte, level int) bool {
+	switch node := child.(type) {
+		case *CMapNode[T]:
+			return cMap.getLeafRecursive(node, key, level) != nil
+		case *CMapCollision:
+			return node.find(key) != -1
+		case CMapLeafNode:
+			return bytes.Equal(node.Key(), key)
+	}
+
+	return false
+}
+
+// filterLeaves 
+//	Keeps the leaves of a leaf or collision node that satisfy a predicate.
+//	The node is reused if every leaf is kept, a single remaining leaf replaces the node, and otherwise a new collision node holds the remaining leaves.
+//
+// Parameters:
+//	child: the leaf or collision node
+//	keep: determines whether a leaf is kept
+//
+// Returns:
+//	The node with the remaining leaves, or nil if none remain
+func (cMap *CMap[T]) filterLeaves(child CMapChildNode, keep func(leaf CMapLeafNode) bool) CMapChildNode {
+	leaves := leavesOf(child)
+	kept := make([]CMapLeafNode, 0, len(leaves))
+	for _, leaf := range leaves {
+		if keep(leaf) { kept = append(kept, leaf) }
+	}
+
+	if len(kept) == len(leaves) { return child }
+	return childFromLeaves(kept)
+}
+
+// nodeFromChildren 
+//	Creates an internal node from a bitmap and the children for its set bits.
+//
+// Parameters:
+//	bitMap: the bitmap of the node
+//	children: the children in order of their sparse index
+//
+// Returns:
+//	The internal node, or nil if there are no children
+func (cMap *CMap[T]) nodeFromChildren(bitMap T, children []CMapChildNode) CMapChildNode {
+	if len(children) == 0 { return nil }
+
+	node := cMap.allocNode(len(children))
+	node.Bitmap = bitMap
+	copy(node.Children, children)
+
+	return node
+}
+
+// sharedBefore 
+//	Counts the sparse indexes below an index set in both bitmaps, so the position in a merged child node array can be derived from the positions in both.
+//
+// Parameters:
+//	a: the first bitmap
+//	b: the second bitmap
+//	index: the sparse index
+//
+// Returns:
+//	The number of shared indexes below the index
+func (cMap *CMap[T]) sharedBefore(a T, b T, index int) int {
+	var mask T = (1 << index) - 1
+	return calculateHammingWeight(a & b & mask)
+}
+
+// tallyNode 
+//	Counts the leaves and bytes below a child of a trie.
+//
+// Parameters:
+//	child: the child to count
+//
+// Returns:
+//	The tally of the leaves below the child
+func (cMap *CMap[T]) tallyNode(child CMapChildNode) cMapTally {
+	var tally cMapTally
+	if node, ok := child.(*CMapNode[T]); ok {
+		cMap.walkLeaves(node, func(leaf CMapLeafNode) bool {
+			tally.addLeaf(leaf)
+			return true
+		})
+
+		return tally
+	}
+
+	for _, leaf := range leavesOf(child) { tally.addLeaf(leaf) }
+	return tally
+}
+
+// newRootFromNode 
+//	Creates a root from a root node built outside of a compare and swap, such as by merging tries.
+//
+// Parameters:
+//	node: the root node, or nil for an empty trie
+//	tally: the leaves and bytes below the root node
+//
+// Returns:
+//	The new root
+func newRootFromNode[T uint32 | uint64](node CMapChildNode, tally cMapTally) *cMapRoot[T] {
+	root := &cMapRoot[T]{ size: tally.size, bytes: tally.bytes }
+	if internalNode, ok := node.(*CMapNode[T]); ok {
+		root.Bitmap = internalNode.Bitmap
+		root.Children = internalNode.Children
+	}
+
+	return root
+}
+
+// leavesOf 
+//	The leaves of a leaf or collision node.
+//
+// Parameters:
+//	child: the leaf or collision node
+//
+// Returns:
+//	The leaves, or nil for an internal node
+func leavesOf(child CMapChildNode) []CMapLeafNode {
+	switch node := child.(type) {
+		case *CMapCollision:
+			return node.Leaves
+		case CMapLeafNode:
+			return []CMapLeafNode{ node }
+	}
+
+	return nil
+}
+
+// childFromLeaves 
+//	Creates the child holding a group of leaves that share a slot.
+//
+// Parameters:
+//	leaves: the leaves
+//
+// Returns:
+//	nil for no leaves, the leaf itself for a single leaf, and otherwise a collision node
+func childFromLeaves(leaves []CMapLeafNode) CMapChildNode {
+	switch len(leaves) {
+		case 0:
+			return nil
+		case 1:
+			return leaves[0]
+		default:
+			return &CMapCollision{ Leaves: leaves }
+	}
+}
+
+// add adds another tally to the tally
+func (tally *cMapTally) add(other cMapTally) {
+	tally.size += other.size
+	tally.bytes += other.bytes
+}
+
+// addLeaf adds a leaf to the tally
+func (tally *cMapTally) addLeaf(leaf CMapLeafNode) {
+	tally.size++
+	tally.bytes += int64(len(leaf.Key()) + len(leaf.Value()))
+}
+
+// subtractLeaf removes a leaf from the tally
+func (tally *cMapTally) subtractLeaf(leaf CMapLeafNode) {
+	tally.size--
+	tally.bytes -= int64(len(leaf.Key()) + len(leaf.Value()))
+}
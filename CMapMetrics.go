@@ -0,0 +1,114 @@
+package cmap
+
+import "errors"
+import "time"
+
+
+//========================================= CMap Metrics
+
+
+// RetryBucketBounds are the upper bounds, inclusive, of the retry histogram buckets
+var RetryBucketBounds = [...]int{ 0, 1, 2, 4, 8, 16, 32, 64 }
+
+// LatencyBucketBounds are the upper bounds, inclusive, of the latency histogram buckets
+var LatencyBucketBounds = [...]time.Duration{ 
+	time.Microsecond, 10 * time.Microsecond, 100 * time.Microsecond, 
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond, time.Second,
+}
+
+
+// NewCMapMetrics 
+//	Initializes the default lock free Metrics implementation.
+//
+// Returns:
+//	The newly initialized metrics, with all counters at 0
+func NewCMapMetrics() *CMapMetrics {
+	return &CMapMetrics{}
+}
+
+// ObserveCASFailure 
+//	Increments the failed compare and swap counter for the operation.
+//
+// Parameters:
+//	op: the operation where the compare and swap failed
+func (metrics *CMapMetrics) ObserveCASFailure(op CMapOp) {
+	metrics.Ops[op].CASFailures.Add(1)
+}
+
+// ObserveOperation 
+//	Records a completed operation, adding it to the retry and latency histograms.
+//	Histogram buckets are cumulative, so an operation is counted in every bucket with a bound greater than or equal to the observed value.
+//
+// Parameters:
+//	op: the completed operation
+//	retries: the number of retries the operation took to complete
+//	latency: the total time the operation took to complete
+func (metrics *CMapMetrics) ObserveOperation(op CMapOp, retries int, latency time.Duration) {
+	opMetrics := &metrics.Ops[op]
+
+	opMetrics.Count.Add(1)
+	opMetrics.Retries.Add(uint64(retries))
+	opMetrics.LatencySum.Add(uint64(latency))
+
+	for idx, bound := range RetryBucketBounds {
+		if retries <= bound { opMetrics.RetryBuckets[idx].Add(1) }
+	}
+
+	opMetrics.RetryBuckets[len(RetryBucketBounds)].Add(1)
+
+	for idx, bound := range LatencyBucketBounds {
+		if latency <= bound { opMetrics.LatencyBuckets[idx].Add(1) }
+	}
+
+	opMetrics.LatencyBuckets[len(LatencyBucketBounds)].Add(1)
+}
+
+// ObserveFailure 
+//	Records an aborted operation, which is counted apart from completed operations and left out of the histograms.
+//
+// Parameters:
+//	op: the aborted operation
+//	err: ErrMaxRetriesExceeded, or the context error
+func (metrics *CMapMetrics) ObserveFailure(op CMapOp, err error) {
+	if errors.Is(err, ErrMaxRetriesExceeded) {
+		metrics.Ops[op].RetriesExhausted.Add(1)
+		return
+	}
+
+	metrics.Ops[op].Cancelled.Add(1)
+}
+
+// String 
+//	The name of the operation, used as a label by the exporters.
+//
+// Returns:
+//	The lowercase name of the operation
+func (op CMapOp) String() string {
+	switch op {
+		case PutOp:
+			return "put"
+		case GetOp:
+			return "get"
+		case DeleteOp:
+			return "delete"
+		default:
+			return "unknown"
+	}
+}
+
+// observe 
+//	Reports the outcome of an operation to the metrics on the map. Deferred at the start of an instrumented operation.
+//
+// Parameters:
+//	op: the operation
+//	start: the time the operation started
+//	retries: a pointer to the retry counter of the operation, read once the operation returns
+//	err: a pointer to the error of the operation, read once the operation returns
+func (cMap *CMap[T]) observe(op CMapOp, start time.Time, retries *int, err *error) {
+	if *err != nil {
+		cMap.Metrics.ObserveFailure(op, *err)
+		return
+	}
+
+	cMap.Metrics.ObserveOperation(op, *retries, time.Since(start))
+}
@@ -0,0 +1,151 @@
+package cmap
+
+import "bytes"
+
+
+//========================================= CMap Nodes
+
+
+// inlineLeafSize is the largest combined key and value length stored inline within a leaf node
+const inlineLeafSize = 30
+
+// maxHashSeeds is the number of times the hash is reseeded before keys that still share a sparse index are stored in a collision node
+const maxHashSeeds = 8
+
+
+func (node *CMapNode[T]) isChildNode() {}
+func (leaf *CMapLeaf) isChildNode() {}
+func (leaf *cMapInlineLeaf) isChildNode() {}
+func (leaf *cMapExpiringLeaf) isChildNode() {}
+func (leaf *cMapCacheLeaf) isChildNode() {}
+func (leaf *cMapMultiLeaf[T]) isChildNode() {}
+func (leaf *cMapCounterLeaf[V]) isChildNode() {}
+func (collision *CMapCollision) isChildNode() {}
+
+// Key returns the key of the leaf
+func (leaf *CMapLeaf) Key() []byte {
+	return leaf.key
+}
+
+// Value returns the value of the leaf
+func (leaf *CMapLeaf) Value() []byte {
+	return leaf.value
+}
+
+// Key returns the key of the leaf, sliced from the inline data
+func (leaf *cMapInlineLeaf) Key() []byte {
+	return leaf.data[:leaf.keyLen:leaf.keyLen]
+}
+
+// Value returns the value of the leaf, sliced from the inline data with the capacity capped so appends do not overwrite the leaf
+func (leaf *cMapInlineLeaf) Value() []byte {
+	end := leaf.keyLen + leaf.valueLen
+	return leaf.data[leaf.keyLen:end:end]
+}
+
+// Key returns the key of the leaf
+func (leaf *cMapExpiringLeaf) Key() []byte {
+	return leaf.key
+}
+
+// Value returns the value of the leaf
+func (leaf *cMapExpiringLeaf) Value() []byte {
+	return leaf.value
+}
+
+// Key returns the key of the leaf
+func (leaf *cMapCacheLeaf) Key() []byte {
+	return leaf.key
+}
+
+// Value returns the value of the leaf
+func (leaf *cMapCacheLeaf) Value() []byte {
+	return leaf.value
+}
+
+// Key returns the key of the leaf
+func (leaf *cMapMultiLeaf[T]) Key() []byte {
+	return leaf.key
+}
+
+// Value returns nil, since the values of the leaf are held in its trie of values
+func (leaf *cMapMultiLeaf[T]) Value() []byte {
+	return nil
+}
+
+// Key returns the key of the leaf
+func (leaf *cMapCounterLeaf[V]) Key() []byte {
+	return leaf.key
+}
+
+// Value returns nil, since the counter is stored natively rather than as bytes
+func (leaf *cMapCounterLeaf[V]) Value() []byte {
+	return nil
+}
+
+// maxLevel 
+//	The deepest level of internal nodes in the trie. Keys that share a sparse index on every level up to this one are stored in a collision node.
+//
+// Returns:
+//	The maximum level
+func (cMap *CMap[T]) maxLevel() int {
+	return cMap.HashChunks * maxHashSeeds
+}
+
+// find 
+//	Searches the leaves of a collision node for a key.
+//
+// Parameters:
+//	key: the key to search for
+//
+// Returns:
+//	The index of the leaf with the key, or -1 if the key is not in the collision node
+func (collision *CMapCollision) find(key []byte) int {
+	for idx, leaf := range collision.Leaves {
+		if bytes.Equal(key, leaf.Key()) { return idx }
+	}
+
+	return -1
+}
+
+// withLeaf 
+//	Creates a copy of a collision node with a leaf added, or replacing the leaf with the same key.
+//
+// Parameters:
+//	leaf: the leaf to add
+//
+// Returns:
+//	The copy of the collision node
+func (collision *CMapCollision) withLeaf(leaf CMapLeafNode) *CMapCollision {
+	idx := collision.find(leaf.Key())
+	if idx == -1 {
+		leaves := make([]CMapLeafNode, len(collision.Leaves), len(collision.Leaves) + 1)
+		copy(leaves, collision.Leaves)
+
+		return &CMapCollision{ Leaves: append(leaves, leaf) }
+	}
+
+	leaves := make([]CMapLeafNode, len(collision.Leaves))
+	copy(leaves, collision.Leaves)
+	leaves[idx] = leaf
+
+	return &CMapCollision{ Leaves: leaves }
+}
+
+// withoutLeaf 
+//	Creates a copy of a collision node with a leaf removed. If a single leaf remains, the leaf replaces the collision node.
+//
+// Parameters:
+//	idx: the index of the leaf to remove
+//
+// Returns:
+//	The remaining leaf, or the copy of the collision node
+func (collision *CMapCollision) withoutLeaf(idx int) CMapChildNode {
+	if len(collision.Leaves) == 2 { return collision.Leaves[1 - idx] }
+
+	leaves := make([]CMapLeafNode, 0, len(collision.Leaves) - 1)
+	leaves = append(leaves, collision.Leaves[:idx]...)
+	leaves = append(leaves, collision.Leaves[idx + 1:]...)
+
+	return &CMapCollision{ Leaves: leaves }
+}
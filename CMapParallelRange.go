@@ -0,0 +1,172 @@
+package cmap
+
+import "context"
+import "runtime"
+import "sync"
+import "sync/atomic"
+
+
+//========================================= CMap Parallel Range
+
+
+// ParallelRange 
+//	Visits every key-value pair of the current version of the map from multiple goroutines. See CMapView.ParallelRange.
+//
+// Parameters:
+//	ctx: the context of the range, which stops every worker when cancelled
+//	workers: the number of goroutines, or below 1 for GOMAXPROCS
+//	fn: called for each key-value pair, from any of the workers. Returning an error stops every worker
+//
+// Returns:
+//	The first error returned by fn, the error of the context if it was cancelled first, or nil
+func (cMap *CMap[T]) ParallelRange(ctx context.Context, workers int, fn func(key []byte, value []byte) error) error {
+	return cMap.View().ParallelRange(ctx, workers, fn)
+}
+
+// ParallelRange 
+//	Visits every key-value pair in the version of the view from multiple goroutines.
+//	The trie is split at the children of the root, 32 or 64 subtrees depending on T, which the workers take in turn, so each subtree is walked by a single goroutine.
+//	The view is a consistent snapshot, so writes to the map during the range are not visited. Expired keys are skipped.
+//
+// Parameters:
+//	ctx: the context of the range, which stops every worker when cancelled
+//	workers: the number of goroutines, or below 1 for GOMAXPROCS
+//	fn: called for each key-value pair, from any of the workers. Returning an error stops every worker
+//
+// Returns:
+//	The first error returned by fn, the error of the context if it was cancelled first, or nil
+func (view *CMapView[T]) ParallelRange(ctx context.Context, workers int, fn func(key []byte, value []byte) error) error {
+	return view.parallel(ctx, workers, func(ctx context.Context, subtree int, child CMapChildNode) error {
+		return view.walkSubtree(ctx, child, func(leaf CMapLeafNode) error { return fn(leaf.Key(), leaf.Value()) })
+	})
+}
+
+// MapReduce 
+//	Maps every key-value pair of the current version of a map to a result and reduces the results, from multiple goroutines.
+//	The trie is split at the children of the root, as with ParallelRange. Each subtree is reduced by a single worker in trie order, then the results of the subtrees are reduced in the order of the root, so reduceFn only needs to be associative.
+//
+// Parameters:
+//	ctx: the context of the reduce, which stops every worker when cancelled
+//	cMap: the map
+//	workers: the number of goroutines, or below 1 for GOMAXPROCS
+//	mapFn: maps a key-value pair to a result. Returning an error stops every worker
+//	reduceFn: combines two results
+//
+// Returns:
+//	The reduced result, or the zero value if the map is empty, and the first error returned by mapFn or the error of the context if it was cancelled first
+func MapReduce[T uint32 | uint64, R any](ctx context.Context, cMap *CMap[T], workers int, mapFn func(key []byte, value []byte) (R, error), reduceFn func(acc R, result R) R) (R, error) {
+	view := cMap.View()
+
+	results := make([]R, len(view.root.Children))
+	reduced := make([]bool, len(view.root.Children))
+	err := view.parallel(ctx, workers, func(ctx context.Context, subtree int, child CMapChildNode) error {
+		return view.walkSubtree(ctx, child, func(leaf CMapLeafNode) error {
+			result, err := mapFn(leaf.Key(), leaf.Value())
+			if err != nil { return err }
+
+			if reduced[subtree] {
+				results[subtree] = reduceFn(results[subtree], result)
+			} else { results[subtree], reduced[subtree] = result, true }
+
+			return nil
+		})
+	})
+
+	var acc R
+	if err != nil { return acc, err }
+
+	accReduced := false
+	for subtree, result := range results {
+		if ! reduced[subtree] { continue }
+
+		if accReduced {
+			acc = reduceFn(acc, result)
+		} else { acc, accReduced = result, true }
+	}
+
+	return acc, nil
+}
+
+// parallel 
+//	Hands the children of the root of the view to a pool of workers, each taking the next unprocessed child until none are left.
+//	The first error cancels the context passed to the other workers, and workers stop taking children once it is cancelled.
+//	Only errors observed by the workers are returned, so a parent context cancelled after every child was processed does not fail the call.
+//
+// Parameters:
+//	ctx: the parent context
+//	workers: the number of goroutines, or below 1 for GOMAXPROCS
+//	process: called for each child of the root with its position in the child node array
+//
+// Returns:
+//	The first error returned by process, the error of the parent context if a worker observed it first, or nil
+func (view *CMapView[T]) parallel(ctx context.Context, workers int, process func(ctx context.Context, subtree int, child CMapChildNode) error) error {
+	if workers < 1 { workers = runtime.GOMAXPROCS(0) }
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	children := view.root.Children
+	next := int64(-1)
+
+	var firstErr error
+	var failOnce sync.Once
+	fail := func(err error) {
+		failOnce.Do(func() { firstErr = err })
+		cancel(err)
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers && worker < len(children); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				subtree := int(atomic.AddInt64(&next, 1))
+				if subtree >= len(children) { return }
+				if ctx.Err() != nil {
+					fail(context.Cause(ctx))
+					return
+				}
+
+				if err := process(ctx, subtree, children[subtree]); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// walkSubtree 
+//	Visits the unexpired leaves below a child of the root in trie order, checking the context before each leaf.
+//
+// Parameters:
+//	ctx: the context of the walk
+//	child: the child of the root
+//	visit: called for each leaf. Returning an error stops the walk
+//
+// Returns:
+//	The error returned by visit, the error of the context if it was cancelled, or nil
+func (view *CMapView[T]) walkSubtree(ctx context.Context, child CMapChildNode, visit func(leaf CMapLeafNode) error) error {
+	now := view.cMap.now()
+
+	var err error
+	view.cMap.walkLeaves(&CMapNode[T]{ Children: []CMapChildNode{ child } }, func(leaf CMapLeafNode) bool {
+		select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				return false
+			default:
+		}
+
+		if view.cMap.isExpired(leaf, now) { return true }
+
+		err = visit(leaf)
+		return err == nil
+	})
+
+	return err
+}
@@ -0,0 +1,230 @@
+package cmap
+
+import "sync/atomic"
+import "unsafe"
+
+
+//========================================= CMap Path
+
+
+// attemptMutation 
+//	A single attempt of a mutation. 
+//	The current root is loaded and the path from the previous attempt is rebased onto it. 
+//	Walking down the path to the key in the current trie, each node is compared to the node at the same level in the previous attempt. 
+//	Since nodes are never modified once published, the first node that is unchanged means the copies made from it and every level below it are still valid, so only the levels above it are copied again. 
+//	If the shape of the path changed, the mutation is rerun from the level where it diverged. 
+//	On the first attempt there is no previous path, so the whole path is built.
+//	If the path has a guard that rejects the current root, the mutation is dropped without modifying the trie, since the compare and swap would be against that root.
+//
+//	The new root carries the entry count and byte size of the previous root, adjusted by the change made by the mutation, and is recorded on the path on success.
+//
+// Parameters:
+//	path: the path from the previous attempt, updated in place
+//	updateFn: the update for the leaf of the key, passed to updateAtNode
+//
+// Returns:
+//	truthy if the compare and swap succeeded, there was nothing to modify, or the guard rejected the root, falsey if the root changed during the attempt
+func (cMap *CMap[T]) attemptMutation(path *cMapPath[T], updateFn func(existing CMapLeafNode) (CMapLeafNode, bool)) bool {
+	currRoot := cMap.loadRoot()
+	if path.guard != nil && ! path.guard(currRoot) {
+		cMap.releaseCopies(path.copies)
+		path.change = cMapChange{}
+		return true
+	}
+
+	level := 0
+	node := &currRoot.CMapNode
+
+	for level < len(path.nodes) && node != path.nodes[level] {
+		child := cMap.internalChild(path, node, level)
+		if child == nil { break }
+
+		path.nodes[level] = node
+		node = child
+		level++
+	}
+
+	validLevel := level
+	if level >= len(path.nodes) || node != path.nodes[level] {
+		path.nodes = path.nodes[:level]
+		cMap.releaseCopies(path.copies)
+		path.copies = path.copies[:0]
+
+		for {
+			path.nodes = append(path.nodes, node)
+			
+			child := cMap.internalChild(path, node, level)
+			if child == nil { break }
+
+			node = child
+			level++
+		}
+
+		bottom := cMap.updateAtNode(path, node, level, updateFn)
+		if bottom == nil { return true }
+
+		for len(path.copies) < len(path.nodes) { path.copies = append(path.copies, nil) }
+		path.copies[level] = bottom
+		validLevel = level
+	}
+
+	cMap.releaseCopies(path.copies[:validLevel])
+	for idx := validLevel - 1; idx >= 0; idx-- {
+		path.copies[idx] = cMap.copyWithChild(path, path.nodes[idx], path.copies[idx + 1], idx)
+	}
+
+	newRoot := cMap.nextRoot(currRoot, path.copies[0], path.change)
+	if ! atomic.CompareAndSwapPointer(&cMap.Root, unsafe.Pointer(currRoot), unsafe.Pointer(newRoot)) { return false }
+
+	path.root = newRoot
+	cMap.retainVersion(newRoot)
+
+	return true
+}
+
+// loadRoot 
+//	Atomically loads the current root of the trie.
+//
+// Returns:
+//	The current root
+func (cMap *CMap[T]) loadRoot() *cMapRoot[T] {
+	return (*cMapRoot[T])(atomic.LoadPointer(&cMap.Root))
+}
+
+// nextRoot 
+//	Creates the root that follows the current root, from the modified copy of the current root. 
+//	The entry count and byte size are adjusted by the change, the sequence is incremented, and the change feed is carried over. If the trie is indexed, the change is applied to the ordered index as well.
+//
+// Parameters:
+//	currRoot: the current root
+//	node: the modified copy of the current root. The child node array is shared with the new root
+//	change: the change made by the mutation
+//
+// Returns:
+//	The new root
+func (cMap *CMap[T]) nextRoot(currRoot *cMapRoot[T], node *CMapNode[T], change cMapChange) *cMapRoot[T] {
+	sizeDelta, bytesDelta := change.deltas()
+	return &cMapRoot[T]{ 
+		CMapNode: CMapNode[T]{ Bitmap: node.Bitmap, Children: node.Children },
+		size: currRoot.size + sizeDelta,
+		bytes: currRoot.bytes + bytesDelta,
+		seq: currRoot.seq + 1,
+		feed: currRoot.feed,
+		indexed: currRoot.indexed,
+		index: currRoot.nextIndex(change),
+	}
+}
+
+// deltas 
+//	The change in entry count and byte size of the trie from a change to a leaf.
+//
+// Returns:
+//	The change in entry count and the change in bytes held in keys and values
+func (change cMapChange) deltas() (int64, int64) {
+	if ! change.applied { return 0, 0 }
+
+	var sizeDelta, bytesDelta int64
+	if change.existing != nil {
+		sizeDelta--
+		bytesDelta -= int64(len(change.existing.Key()) + len(change.existing.Value()))
+	}
+
+	if change.leaf != nil {
+		sizeDelta++
+		bytesDelta += int64(len(change.leaf.Key()) + len(change.leaf.Value()))
+	}
+
+	return sizeDelta, bytesDelta
+}
+
+// newPath 
+//	Creates an empty path for a mutation on a key.
+//
+// Parameters:
+//	key: the key being modified
+//
+// Returns:
+//	The empty path
+func (cMap *CMap[T]) newPath(key []byte) *cMapPath[T] {
+	path := &cMapPath[T]{ key: key }
+	path.hashes = path.hashesBuf[:0]
+	path.nodes = path.nodesBuf[:0]
+	path.copies = path.copiesBuf[:0]
+
+	return path
+}
+
+// pathHash 
+//	Gets the hash of the key on the path for a level, calculating it only the first time the chunk of levels containing the level is reached.
+//
+// Parameters:
+//	path: the path of the mutation
+//	level: the level within the trie
+//
+// Returns:
+//	The hash of the key for the level
+func (cMap *CMap[T]) pathHash(path *cMapPath[T], level int) T {
+	for chunk := len(path.hashes); chunk <= level / cMap.HashChunks; chunk++ {
+		path.hashes = append(path.hashes, cMap.CalculateHashForCurrentLevel(path.key, chunk * cMap.HashChunks))
+	}
+
+	return path.hashes[level / cMap.HashChunks]
+}
+
+// releaseCopies 
+//	Returns copies from a failed attempt to the node pool. The copies were never published, since the compare and swap on the root failed.
+//
+// Parameters:
+//	copies: the discarded copies
+func (cMap *CMap[T]) releaseCopies(copies []*CMapNode[T]) {
+	for idx, nodeCopy := range copies {
+		cMap.releaseNode(nodeCopy)
+		copies[idx] = nil
+	}
+}
+
+// internalChild 
+//	Gets the child on the path to the key if it is an internal node.
+//
+// Parameters:
+//	path: the path of the mutation
+//	node: the current node on the path
+//	level: the level of the current node
+//
+// Returns:
+//	The internal child node, or nil if the slot for the key is empty or holds a leaf or collision node
+func (cMap *CMap[T]) internalChild(path *cMapPath[T], node *CMapNode[T], level int) *CMapNode[T] {
+	hash := cMap.pathHash(path, level)
+	index := cMap.getSparseIndex(hash, level)
+	if ! IsBitSet(node.Bitmap, index) { return nil }
+
+	child, isInternal := node.Children[cMap.getPosition(node.Bitmap, hash, level)].(*CMapNode[T])
+	if ! isInternal { return nil }
+
+	return child
+}
+
+// copyWithChild 
+//	Creates a copy of a node on the path with the child on the path to the key replaced by its modified copy. 
+//	If the modified child no longer has any children, it is removed from the copy instead.
+//
+// Parameters:
+//	path: the path of the mutation
+//	node: the node on the path to copy
+//	child: the modified copy of the child on the path
+//	level: the level of the node
+//
+// Returns:
+//	The copy of the node pointing to the modified child
+func (cMap *CMap[T]) copyWithChild(path *cMapPath[T], node *CMapNode[T], child *CMapNode[T], level int) *CMapNode[T] {
+	hash := cMap.pathHash(path, level)
+	index := cMap.getSparseIndex(hash, level)
+	pos := cMap.getPosition(node.Bitmap, hash, level)
+
+	if len(child.Children) == 0 { return cMap.copyNodeWithRemove(node, SetBit(node.Bitmap, index), pos) }
+
+	nodeCopy := cMap.CopyNode(node)
+	nodeCopy.Children[pos] = child
+
+	return nodeCopy
+}
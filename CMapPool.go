@@ -0,0 +1,95 @@
+package cmap
+
+
+//========================================= CMap Pool
+
+
+// smallFanout is the largest child node array that is allocated inline with its node
+const smallFanout = 4
+
+
+// allocNode 
+//	Allocates an internal node with a child node array of the given size. 
+//	Discarded copies from failed compare and swap attempts are reused first if their child node array is large enough. 
+//	Otherwise, for small fanout the node and a fixed size child node array are allocated together, so the copy is a single allocation.
+//
+// Parameters:
+//	size: the length of the child node array
+//
+// Returns:
+//	The node, with all fields other than the length of the child node array left to the caller to set
+func (cMap *CMap[T]) allocNode(size int) *CMapNode[T] {
+	if pooled, ok := cMap.nodePool.Get().(*CMapNode[T]); ok {
+		if cap(pooled.Children) >= size {
+			pooled.Children = pooled.Children[:size]
+			return pooled
+		}
+	}
+
+	if size <= smallFanout {
+		small := &cMapSmallNode[T]{}
+		small.node.Children = small.children[:size]
+		
+		return &small.node
+	}
+
+	return &CMapNode[T]{ Children: make([]CMapChildNode, size) }
+}
+
+// releaseNode 
+//	Returns a copy that was never published to the pool. 
+//	The child node array is cleared so the pool does not keep nodes in the trie, or removed nodes, from being garbage collected.
+//
+// Parameters:
+//	node: the discarded copy
+func (cMap *CMap[T]) releaseNode(node *CMapNode[T]) {
+	if node == nil { return }
+
+	children := node.Children[:cap(node.Children)]
+	for idx := range children { children[idx] = nil }
+	node.owner = nil
+	
+	cMap.nodePool.Put(node)
+}
+
+// copyNodeWithInsert 
+//	Creates a copy of an internal node with a new child inserted into the child node array, sizing the array once instead of copying and then extending it.
+//
+// Parameters:
+//	node: the node to copy
+//	bitMap: the bitmap of the copy, with the bit for the new child set
+//	pos: the position in the child node array for the new child
+//	newNode: the child to insert
+//
+// Returns:
+//	The copy with the new child
+func (cMap *CMap[T]) copyNodeWithInsert(node *CMapNode[T], bitMap T, pos int, newNode CMapChildNode) *CMapNode[T] {
+	nodeCopy := cMap.allocNode(len(node.Children) + 1)
+	nodeCopy.Bitmap = bitMap
+
+	copy(nodeCopy.Children[:pos], node.Children[:pos])
+	nodeCopy.Children[pos] = newNode
+	copy(nodeCopy.Children[pos + 1:], node.Children[pos:])
+
+	return nodeCopy
+}
+
+// copyNodeWithRemove 
+//	Creates a copy of an internal node with a child removed from the child node array, sizing the array once instead of copying and then shrinking it.
+//
+// Parameters:
+//	node: the node to copy
+//	bitMap: the bitmap of the copy, with the bit for the removed child unset
+//	pos: the position in the child node array of the child to remove
+//
+// Returns:
+//	The copy without the child
+func (cMap *CMap[T]) copyNodeWithRemove(node *CMapNode[T], bitMap T, pos int) *CMapNode[T] {
+	nodeCopy := cMap.allocNode(len(node.Children) - 1)
+	nodeCopy.Bitmap = bitMap
+
+	copy(nodeCopy.Children[:pos], node.Children[:pos])
+	copy(nodeCopy.Children[pos:], node.Children[pos + 1:])
+
+	return nodeCopy
+}
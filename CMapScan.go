@@ -0,0 +1,121 @@
+package cmap
+
+
+//========================================= CMap Scan
+
+
+// Scan 
+//	Visits a page of keys for a stateless cursor, where each call reads the current version of the map.
+//	The cursor is the path of sparse indexes from the root to the next slot to visit, with the index of the root level in the highest bits and each level below in the next BitChunkSize bits.
+//	Slots are visited in order of their paths, which is the order of the sparse indexes of the keys and does not depend on the shape of the trie, so moving to the next slot increments the cursor at the level of the slot and carries into the levels above, like the reverse binary cursor of Redis SCAN.
+//	Every key present for the whole scan is returned exactly once, since keys are visited in the order of their own paths no matter how concurrent writes split or remove nodes between calls.
+//	Paths are kept to the levels that fit in 64 bits, 12 for a 32 bit map and 10 for a 64 bit map, and a subtree below the deepest level is returned in a single page.
+//
+// Parameters:
+//	cursor: 0 to start a scan, or the cursor returned by the previous call
+//	count: the number of keys after which the page ends at the next slot boundary, so a page may hold more. Values below 1 are treated as 1
+//
+// Returns:
+//	The unexpired keys in the page, and the cursor for the next call, which is 0 when the scan is complete
+func (cMap *CMap[T]) Scan(cursor uint64, count int) ([][]byte, uint64) {
+	if count < 1 { count = 1 }
+
+	scan := &cMapScan{ cursor: cursor, count: count, now: cMap.now() }
+	nextCursor, _ := cMap.scanNode(&cMap.loadRoot().CMapNode, 0, 0, true, scan)
+
+	return scan.keys, nextCursor
+}
+
+// scanNode 
+//	Collects the keys below a node in order of the sparse indexes of its children, starting from the slot of the cursor if the node is on the path of the cursor.
+//
+// Parameters:
+//	node: the internal node
+//	level: the level of the node within the trie
+//	path: the cursor bits of the slots above the node
+//	onPath: truthy if the path of the node is the path of the cursor
+//	scan: the state of the scan
+//
+// Returns:
+//	The cursor of the slot after the last slot visited and truthy if the page is full, or 0 and falsey if every slot below the node was visited
+func (cMap *CMap[T]) scanNode(node *CMapNode[T], level int, path uint64, onPath bool, scan *cMapScan) (uint64, bool) {
+	shift := cMap.scanShift(level)
+	start := 0
+	if onPath { start = int(scan.cursor >> shift) & (1 << cMap.BitChunkSize - 1) }
+
+	pos := 0
+	for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+		if ! IsBitSet(node.Bitmap, index) { continue }
+
+		child := node.Children[pos]
+		pos++
+		if index < start { continue }
+
+		slot := path | uint64(index) << shift
+		filter := onPath && index == start
+
+		switch childNode := child.(type) {
+			case *CMapNode[T]:
+				if level + 1 < cMap.scanDepth() {
+					nextCursor, full := cMap.scanNode(childNode, level + 1, slot, filter, scan)
+					if full { return nextCursor, true }
+				} else {
+					cMap.walkLeaves(childNode, func(leaf CMapLeafNode) bool {
+						cMap.scanLeaf(leaf, filter, scan)
+						return true
+					})
+				}
+			case *CMapCollision:
+				for _, leaf := range childNode.Leaves { cMap.scanLeaf(leaf, filter, scan) }
+			case CMapLeafNode:
+				cMap.scanLeaf(childNode, filter, scan)
+		}
+
+		if len(scan.keys) >= scan.count { return slot + 1 << shift, true }
+	}
+
+	return 0, false
+}
+
+// scanLeaf 
+//	Collects the key of an unexpired leaf.
+//	A leaf on the path of the cursor may sit above the level the cursor reaches, after a delete removed the nodes below it, so its key is only collected if its own cursor is not before the cursor of the scan.
+//
+// Parameters:
+//	leaf: the leaf
+//	filter: truthy if the leaf is in the slot of the cursor
+//	scan: the state of the scan
+func (cMap *CMap[T]) scanLeaf(leaf CMapLeafNode, filter bool, scan *cMapScan) {
+	if cMap.isExpired(leaf, scan.now) { return }
+	if filter && cMap.scanCursor(leaf.Key()) < scan.cursor { return }
+
+	scan.keys = append(scan.keys, leaf.Key())
+}
+
+// scanCursor 
+//	Calculates the cursor of a key, which is the path of its sparse indexes down to the deepest level a cursor holds.
+//
+// Parameters:
+//	key: the key
+//
+// Returns:
+//	The cursor of the key
+func (cMap *CMap[T]) scanCursor(key []byte) uint64 {
+	cursor := uint64(0)
+	for level := 0; level < cMap.scanDepth(); level++ {
+		hash := cMap.CalculateHashForCurrentLevel(key, level)
+		cursor |= uint64(cMap.getSparseIndex(hash, level)) << cMap.scanShift(level)
+	}
+
+	return cursor
+}
+
+// scanDepth is the number of levels held in a cursor
+func (cMap *CMap[T]) scanDepth() int {
+	return 64 / cMap.BitChunkSize
+}
+
+// scanShift is the position of the sparse index for a level within a cursor
+func (cMap *CMap[T]) scanShift(level int) int {
+	return 64 - (level + 1) * cMap.BitChunkSize
+}
@@ -0,0 +1,288 @@
+package cmap
+
+import "context"
+import "io"
+import "math/bits"
+import "time"
+import "unsafe"
+
+
+//========================================= Sharded CMap
+
+
+// NewShardedCMap 
+//	Creates a map split into shards, each an independent trie with its own root.
+//	The shard of a key is selected by the top bits of its hash, seeded separately from the hashes used within the tries, so keys are spread evenly within each shard as well.
+//
+// Parameters:
+//	shards: the number of shards, rounded up to a power of two and capped at one shard for each value of the hash. If 1 or less, the map has a single shard
+//
+// Returns:
+//	The new sharded map
+func NewShardedCMap[T uint32 | uint64](shards int) *ShardedCMap[T] {
+	var hash T
+	hashBits := int(unsafe.Sizeof(hash) * 8)
+
+	shardBits := 0
+	if shards > 1 { shardBits = bits.Len(uint(shards - 1)) }
+	if shardBits > hashBits { shardBits = hashBits }
+
+	shardedMap := &ShardedCMap[T]{ Shards: make([]*CMap[T], 1 << shardBits), shardBits: shardBits }
+	for idx := range shardedMap.Shards { shardedMap.Shards[idx] = NewCMap[T]() }
+
+	return shardedMap
+}
+
+// Shard 
+//	Gets the shard a key belongs to. The hash selecting the shard is calculated once per operation.
+//
+// Parameters:
+//	key: the key
+//
+// Returns:
+//	The trie of the shard
+func (shardedMap *ShardedCMap[T]) Shard(key []byte) *CMap[T] {
+	if shardedMap.shardBits == 0 { return shardedMap.Shards[0] }
+
+	var hash T
+	switch any(hash).(type) {
+		case uint64:
+			hash = (T)(Murmur64(key, 0))
+		default:
+			hash = (T)(Murmur32(key, 0))
+	}
+
+	shift := int(unsafe.Sizeof(hash) * 8) - shardedMap.shardBits
+	return shardedMap.Shards[int(hash >> shift)]
+}
+
+// Put 
+//	Inserts or updates a key-value pair in the shard of the key. See CMap.Put.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//
+// Returns:
+//	truthy on successful completion, or falsey if MaxRetries was exceeded
+func (shardedMap *ShardedCMap[T]) Put(key []byte, value []byte) bool {
+	return shardedMap.Shard(key).Put(key, value)
+}
+
+// PutContext 
+//	Same as Put, but the retry loop is aborted if the context is cancelled.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//
+// Returns:
+//	nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (shardedMap *ShardedCMap[T]) PutContext(ctx context.Context, key []byte, value []byte) error {
+	return shardedMap.Shard(key).PutContext(ctx, key, value)
+}
+
+// PutWithTTL 
+//	Inserts or updates a key-value pair that expires after a duration. See CMap.PutWithTTL.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//	ttl: the time to live of the key-value pair. If 0 or less, the key-value pair does not expire
+//
+// Returns:
+//	truthy on successful completion, or falsey if MaxRetries was exceeded
+func (shardedMap *ShardedCMap[T]) PutWithTTL(key []byte, value []byte, ttl time.Duration) bool {
+	return shardedMap.Shard(key).PutWithTTL(key, value, ttl)
+}
+
+// PutWithOptions 
+//	Inserts or updates a key-value pair if its condition holds. See CMap.PutWithOptions.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//	options: the condition and time to live of the put
+//
+// Returns:
+//	truthy if the key-value pair was written, and nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (shardedMap *ShardedCMap[T]) PutWithOptions(ctx context.Context, key []byte, value []byte, options CMapPutOptions) (bool, error) {
+	return shardedMap.Shard(key).PutWithOptions(ctx, key, value, options)
+}
+
+// Get 
+//	Gets the value for a key from the shard of the key.
+//
+// Parameters:
+//	key: the key to look up
+//
+// Returns:
+//	The value for the key, or nil if not present
+func (shardedMap *ShardedCMap[T]) Get(key []byte) []byte {
+	return shardedMap.Shard(key).Get(key)
+}
+
+// Delete 
+//	Deletes a key-value pair from the shard of the key.
+//
+// Parameters:
+//	key: the key to delete
+//
+// Returns:
+//	truthy on successful completion, or falsey if MaxRetries was exceeded
+func (shardedMap *ShardedCMap[T]) Delete(key []byte) bool {
+	return shardedMap.Shard(key).Delete(key)
+}
+
+// DeleteContext 
+//	Same as Delete, but the retry loop is aborted if the context is cancelled.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key to delete
+//
+// Returns:
+//	nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (shardedMap *ShardedCMap[T]) DeleteContext(ctx context.Context, key []byte) error {
+	return shardedMap.Shard(key).DeleteContext(ctx, key)
+}
+
+// LoadAndDelete 
+//	Deletes a key-value pair, returning the value it held. See CMap.LoadAndDelete.
+//
+// Parameters:
+//	key: the key to delete
+//
+// Returns:
+//	The deleted value, and truthy if the key was present
+func (shardedMap *ShardedCMap[T]) LoadAndDelete(key []byte) ([]byte, bool) {
+	return shardedMap.Shard(key).LoadAndDelete(key)
+}
+
+// CompareAndSwap 
+//	Replaces the value for a key only if it currently holds the old value. See CMap.CompareAndSwap.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	old: the value the key must hold
+//	new: the replacement value
+//
+// Returns:
+//	truthy if the value was swapped
+func (shardedMap *ShardedCMap[T]) CompareAndSwap(key []byte, old []byte, new []byte) bool {
+	return shardedMap.Shard(key).CompareAndSwap(key, old, new)
+}
+
+// CompareAndDelete 
+//	Deletes a key only if it currently holds the old value. See CMap.CompareAndDelete.
+//
+// Parameters:
+//	key: the key to delete
+//	old: the value the key must hold
+//
+// Returns:
+//	truthy if the key was deleted
+func (shardedMap *ShardedCMap[T]) CompareAndDelete(key []byte, old []byte) bool {
+	return shardedMap.Shard(key).CompareAndDelete(key, old)
+}
+
+// SweepExpired 
+//	Deletes expired key-value pairs in every shard.
+//
+// Returns:
+//	The total key-value pairs deleted
+func (shardedMap *ShardedCMap[T]) SweepExpired() int {
+	deleted := 0
+	for _, shard := range shardedMap.Shards { deleted += shard.SweepExpired() }
+
+	return deleted
+}
+
+// Len 
+//	The total key-value pairs across the shards. Each shard is read atomically, but shards are read one after another.
+//
+// Returns:
+//	The total key-value pairs
+func (shardedMap *ShardedCMap[T]) Len() int {
+	total := 0
+	for _, shard := range shardedMap.Shards { total += shard.Len() }
+
+	return total
+}
+
+// Bytes 
+//	The total bytes held in keys and values across the shards.
+//
+// Returns:
+//	The total bytes
+func (shardedMap *ShardedCMap[T]) Bytes() int64 {
+	var total int64
+	for _, shard := range shardedMap.Shards { total += shard.Bytes() }
+
+	return total
+}
+
+// Views 
+//	Takes a view of every shard. Each view is a consistent version of its shard, but the views are taken one after another, so writes spanning shards may be partially visible.
+//
+// Returns:
+//	The views of the shards, in shard order
+func (shardedMap *ShardedCMap[T]) Views() []*CMapView[T] {
+	views := make([]*CMapView[T], len(shardedMap.Shards))
+	for idx, shard := range shardedMap.Shards { views[idx] = shard.View() }
+
+	return views
+}
+
+// Range 
+//	Visits every key-value pair across the shards, in shard order and then trie order.
+//	The views of every shard are taken before iterating, so writes made during the iteration are not visited.
+//
+// Parameters:
+//	fn: called for each key-value pair. Returning falsey stops the iteration
+func (shardedMap *ShardedCMap[T]) Range(fn func(key []byte, value []byte) bool) {
+	stopped := false
+	for _, view := range shardedMap.Views() {
+		view.Range(func(key []byte, value []byte) bool {
+			stopped = ! fn(key, value)
+			return ! stopped
+		})
+
+		if stopped { return }
+	}
+}
+
+// WriteSnapshot 
+//	Writes the shards as a single snapshot, from views of every shard taken before writing.
+//	The snapshot has the same format as CMap.WriteSnapshot, so it can be read into a CMap with ReadSnapshot or into a sharded map with any number of shards with ReadShardedSnapshot.
+//
+// Parameters:
+//	w: the writer for the snapshot
+//
+// Returns:
+//	The error writing the snapshot
+func (shardedMap *ShardedCMap[T]) WriteSnapshot(w io.Writer) error {
+	return writeSnapshot(w, shardedMap.Views())
+}
+
+// ReadShardedSnapshot 
+//	Reads a snapshot into a new sharded map, redistributing keys across the shards. Keys that expired since the snapshot was written are skipped.
+//	The sequence of each shard starts from the key-value pairs loaded into it, since the sequence of the snapshot cannot be split across shards.
+//
+// Parameters:
+//	r: the reader for the snapshot
+//	shards: the number of shards, rounded up to a power of two
+//
+// Returns:
+//	The new sharded map, and ErrInvalidSnapshot if the snapshot is malformed or fails its checksum, ErrSnapshotBits if the hash width does not match, or the error reading
+func ReadShardedSnapshot[T uint32 | uint64](r io.Reader, shards int) (*ShardedCMap[T], error) {
+	shardedMap := NewShardedCMap[T](shards)
+	_, err := readSnapshot[T](r, func(key []byte, value []byte, expiresAt int64) error {
+		return shardedMap.Shard(key).loadLeaf(key, value, expiresAt)
+	})
+
+	if err != nil { return nil, err }
+	return shardedMap, nil
+}
@@ -0,0 +1,314 @@
+package cmap
+
+import "bufio"
+import "bytes"
+import "context"
+import "encoding/binary"
+import "errors"
+import "fmt"
+import "hash"
+import "hash/crc32"
+import "io"
+import "unsafe"
+
+
+//========================================= CMap Snapshot
+
+
+// snapshotMagic identifies a snapshot file
+const snapshotMagic = "CMAPSNAP"
+
+// snapshotFormat is the version of the snapshot format
+const snapshotFormat = 1
+
+// snapshotExpiring is the entry flag for key-value pairs with an expiry
+const snapshotExpiring = 1
+
+// maxSnapshotField is the largest key or value accepted when reading a snapshot
+const maxSnapshotField = 512 << 20
+
+// snapshotReadChunk is the largest field read with a single allocation. Longer fields grow as their bytes arrive, so a corrupt length does not cause a huge allocation
+const snapshotReadChunk = 64 << 10
+
+// ErrInvalidSnapshot is returned when reading a snapshot that is malformed, truncated, or fails its checksum
+var ErrInvalidSnapshot = errors.New("cmap: invalid snapshot")
+
+// ErrSnapshotBits is returned when reading a snapshot into a map with a different hash width
+var ErrSnapshotBits = errors.New("cmap: snapshot hash width does not match map")
+
+var snapshotTable = crc32.MakeTable(crc32.Castagnoli)
+
+
+// WriteSnapshot 
+//	Writes the current version of the trie to a writer. See CMapView.WriteSnapshot.
+//
+// Parameters:
+//	w: the writer for the snapshot
+//
+// Returns:
+//	The error writing the snapshot
+func (cMap *CMap[T]) WriteSnapshot(w io.Writer) error {
+	return cMap.View().WriteSnapshot(w)
+}
+
+// WriteSnapshot 
+//	Writes the trie as of the version of the view to a writer. 
+//	The snapshot is a header with the hash width, sequence and entry count, followed by each key-value pair with its expiry, and a crc32c checksum of everything before it.
+//
+// Parameters:
+//	w: the writer for the snapshot
+//
+// Returns:
+//	The error writing the snapshot
+func (view *CMapView[T]) WriteSnapshot(w io.Writer) error {
+	return writeSnapshot(w, []*CMapView[T]{ view })
+}
+
+// ReadSnapshot 
+//	Reads a snapshot into a new map. Keys that expired since the snapshot was written are skipped. 
+//	The new map starts at the sequence of the snapshot, so later changes continue from it.
+//
+// Parameters:
+//	r: the reader for the snapshot
+//
+// Returns:
+//	The new map, and ErrInvalidSnapshot if the snapshot is malformed or fails its checksum, ErrSnapshotBits if the hash width does not match, or the error reading
+func ReadSnapshot[T uint32 | uint64](r io.Reader) (*CMap[T], error) {
+	cMap := NewCMap[T]()
+	seq, err := readSnapshot[T](r, func(key []byte, value []byte, expiresAt int64) error { 
+		return cMap.loadLeaf(key, value, expiresAt) 
+	})
+
+	if err != nil { return nil, err }
+
+	cMap.loadRoot().seq = seq
+	return cMap, nil
+}
+
+// writeSnapshot 
+//	Writes the tries of one or more views to a writer as a single snapshot. The sequence of the snapshot is the sum of the sequences of the views.
+//
+// Parameters:
+//	w: the writer for the snapshot
+//	views: the views to write
+//
+// Returns:
+//	The error writing the snapshot
+func writeSnapshot[T uint32 | uint64](w io.Writer, views []*CMapView[T]) error {
+	bufWriter := bufio.NewWriter(w)
+	checksum := crc32.New(snapshotTable)
+	out := io.MultiWriter(bufWriter, checksum)
+
+	var seq, count uint64
+	for _, view := range views {
+		seq += view.root.seq
+		count += uint64(view.root.size)
+	}
+
+	var zero T
+	header := make([]byte, 0, len(snapshotMagic) + 10 + binary.MaxVarintLen64)
+	header = append(header, snapshotMagic...)
+	header = append(header, snapshotFormat, byte(unsafe.Sizeof(zero) * 8))
+	header = binary.LittleEndian.AppendUint64(header, seq)
+	header = binary.AppendUvarint(header, count)
+	if _, err := out.Write(header); err != nil { return err }
+
+	var writeErr error
+	entry := []byte{}
+	for _, view := range views {
+		view.cMap.walkLeaves(&view.root.CMapNode, func(leaf CMapLeafNode) bool {
+			entry = entry[:0]
+			if expiringLeaf, ok := leaf.(*cMapExpiringLeaf); ok {
+				entry = append(entry, snapshotExpiring)
+				entry = binary.LittleEndian.AppendUint64(entry, uint64(expiringLeaf.expiresAt))
+			} else { entry = append(entry, 0) }
+
+			entry = binary.AppendUvarint(entry, uint64(len(leaf.Key())))
+			entry = append(entry, leaf.Key()...)
+			entry = binary.AppendUvarint(entry, uint64(len(leaf.Value())))
+			entry = append(entry, leaf.Value()...)
+
+			_, writeErr = out.Write(entry)
+			return writeErr == nil
+		})
+
+		if writeErr != nil { return writeErr }
+	}
+
+	if _, err := bufWriter.Write(binary.LittleEndian.AppendUint32(nil, checksum.Sum32())); err != nil { return err }
+
+	return bufWriter.Flush()
+}
+
+// readSnapshot 
+//	Reads the entries of a snapshot, validating its header and checksum.
+//
+// Parameters:
+//	r: the reader for the snapshot
+//	load: called for each key-value pair with its expiry in unix nanoseconds, or 0 if it does not expire
+//
+// Returns:
+//	The sequence of the snapshot, and ErrInvalidSnapshot, ErrSnapshotBits, the error reading, or the error from load
+func readSnapshot[T uint32 | uint64](r io.Reader, load func(key []byte, value []byte, expiresAt int64) error) (uint64, error) {
+	checksum := crc32.New(snapshotTable)
+	reader := &snapshotReader{ reader: bufio.NewReader(r), checksum: checksum }
+
+	bits, seq, count, err := reader.header()
+	if err != nil { return 0, err }
+
+	var zero T
+	if bits != int(unsafe.Sizeof(zero) * 8) { return 0, ErrSnapshotBits }
+
+	for entry := uint64(0); entry < count; entry++ {
+		flags, err := reader.byte()
+		if err != nil { return 0, err }
+
+		var expiresAt int64
+		if flags & snapshotExpiring != 0 {
+			expiresAtBytes, err := reader.bytes(8)
+			if err != nil { return 0, err }
+
+			expiresAt = int64(binary.LittleEndian.Uint64(expiresAtBytes))
+		}
+
+		key, err := reader.lengthPrefixed()
+		if err != nil { return 0, err }
+
+		value, err := reader.lengthPrefixed()
+		if err != nil { return 0, err }
+
+		if err := load(key, value, expiresAt); err != nil { return 0, err }
+	}
+
+	sum := checksum.Sum32()
+	footer, err := reader.bytes(4)
+	if err != nil { return 0, err }
+	if binary.LittleEndian.Uint32(footer) != sum { return 0, fmt.Errorf("%w: checksum mismatch", ErrInvalidSnapshot) }
+
+	return seq, nil
+}
+
+// loadLeaf 
+//	Inserts a key-value pair read from a snapshot, skipping it if it has already expired.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//	expiresAt: the expiry of the key-value pair in unix nanoseconds, or 0 if it does not expire
+//
+// Returns:
+//	The error inserting the key-value pair
+func (cMap *CMap[T]) loadLeaf(key []byte, value []byte, expiresAt int64) error {
+	if expiresAt != 0 && expiresAt <= cMap.now() { return nil }
+
+	_, err := cMap.update(context.Background(), PutOp, key, func(CMapLeafNode) (CMapLeafNode, bool) {
+		if expiresAt != 0 { return &cMapExpiringLeaf{ key: key, value: value, expiresAt: expiresAt }, true }
+		return cMap.NewLeafNode(key, value), true
+	})
+
+	return err
+}
+
+// SnapshotBits 
+//	Reads the hash width of a snapshot from its header, so the snapshot can be read into a map of the same width.
+//
+// Parameters:
+//	r: the reader for the snapshot, positioned at the start of the snapshot
+//
+// Returns:
+//	32 or 64, and ErrInvalidSnapshot if the header is malformed
+func SnapshotBits(r io.Reader) (int, error) {
+	reader := &snapshotReader{ reader: bufio.NewReader(r), checksum: crc32.New(snapshotTable) }
+	bits, _, _, err := reader.header()
+
+	return bits, err
+}
+
+// snapshotReader 
+//	Reads the fields of a snapshot, adding every byte read to the checksum.
+//
+// Properties
+//	reader: the buffered reader for the snapshot
+//	checksum: the running checksum of the bytes read
+type snapshotReader struct {
+	reader *bufio.Reader
+	checksum hash.Hash32
+}
+
+// header reads and validates the header, returning the hash width, sequence and entry count
+func (reader *snapshotReader) header() (int, uint64, uint64, error) {
+	magic, err := reader.bytes(uint64(len(snapshotMagic) + 2))
+	if err != nil { return 0, 0, 0, err }
+	if ! bytes.Equal(magic[:len(snapshotMagic)], []byte(snapshotMagic)) { return 0, 0, 0, fmt.Errorf("%w: not a snapshot", ErrInvalidSnapshot) }
+	if magic[len(snapshotMagic)] != snapshotFormat { return 0, 0, 0, fmt.Errorf("%w: unsupported format %d", ErrInvalidSnapshot, magic[len(snapshotMagic)]) }
+
+	bits := int(magic[len(snapshotMagic) + 1])
+	if bits != 32 && bits != 64 { return 0, 0, 0, fmt.Errorf("%w: unsupported hash width %d", ErrInvalidSnapshot, bits) }
+
+	seqBytes, err := reader.bytes(8)
+	if err != nil { return 0, 0, 0, err }
+
+	count, err := reader.uvarint()
+	if err != nil { return 0, 0, 0, err }
+
+	return bits, binary.LittleEndian.Uint64(seqBytes), count, nil
+}
+
+// byte reads a single byte
+func (reader *snapshotReader) byte() (byte, error) {
+	value, err := reader.bytes(1)
+	if err != nil { return 0, err }
+
+	return value[0], nil
+}
+
+// bytes reads a fixed number of bytes, allocating no more than the bytes actually read for long fields
+func (reader *snapshotReader) bytes(n uint64) ([]byte, error) {
+	if n > maxSnapshotField { return nil, fmt.Errorf("%w: length %d too large", ErrInvalidSnapshot, n) }
+
+	if n <= snapshotReadChunk {
+		value := make([]byte, n)
+		if _, err := io.ReadFull(reader.reader, value); err != nil { return nil, truncated(err) }
+
+		reader.checksum.Write(value)
+		return value, nil
+	}
+
+	var buffer bytes.Buffer
+	if _, err := io.CopyN(&buffer, reader.reader, int64(n)); err != nil { return nil, truncated(err) }
+
+	reader.checksum.Write(buffer.Bytes())
+	return buffer.Bytes(), nil
+}
+
+// uvarint reads a variable length unsigned integer
+func (reader *snapshotReader) uvarint() (uint64, error) {
+	value, err := binary.ReadUvarint(&checksumByteReader{ reader: reader })
+	if err != nil { return 0, truncated(err) }
+
+	return value, nil
+}
+
+// lengthPrefixed reads a variable length byte array prefixed by its length
+func (reader *snapshotReader) lengthPrefixed() ([]byte, error) {
+	length, err := reader.uvarint()
+	if err != nil { return nil, err }
+
+	return reader.bytes(length)
+}
+
+// checksumByteReader adapts the snapshot reader to io.ByteReader, adding each byte to the checksum
+type checksumByteReader struct {
+	reader *snapshotReader
+}
+
+// ReadByte reads a single byte
+func (byteReader *checksumByteReader) ReadByte() (byte, error) {
+	return byteReader.reader.byte()
+}
+
+// truncated maps an unexpected end of the snapshot to ErrInvalidSnapshot
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF { return fmt.Errorf("%w: truncated", ErrInvalidSnapshot) }
+	return err
+}
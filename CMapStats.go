@@ -0,0 +1,123 @@
+package cmap
+
+import "sync/atomic"
+import "unsafe"
+
+
+//========================================= CMap Stats
+
+
+// Stats 
+//	Walks the hash array mapped trie from the current root and collects structural statistics. 
+//	Since the trie is path copied, the walk operates on the root at the point in time the operation began, so concurrent writes do not affect the result.
+//
+// Returns:
+//	The statistics for the trie
+func (cMap *CMap[T]) Stats() *CMapStats {
+	stats := &CMapStats{ Levels: []CMapLevelStats{} }
+	
+	currRoot := (*CMapNode[T])(atomic.LoadPointer(&cMap.Root))
+	cMap.statsRecursive(currRoot, 0, stats)
+
+	totalDepth := 0
+	for _, levelStats := range stats.Levels {
+		totalDepth += levelStats.Leaves * levelStats.Level
+	}
+
+	if stats.LeafCount > 0 { stats.AverageDepth = float64(totalDepth) / float64(stats.LeafCount) }
+	if stats.MaxDepth > 0 { stats.Reseeds = (stats.MaxDepth - 1) / cMap.HashChunks }
+
+	return stats
+}
+
+// statsRecursive
+//	Visits an internal node and its children, updating the level statistics for the level of the node and the level below it. 
+//	Leaf nodes are counted at the level of the child node array they reside in, so a leaf in the root's child node array is at level 1.
+//
+// Parameters:
+//	node: the internal node being visited
+//	level: the level of the internal node
+//	stats: the statistics being collected
+func (cMap *CMap[T]) statsRecursive(node *CMapNode[T], level int, stats *CMapStats) {
+	cMap.levelStats(stats, level).InternalNodes++
+	stats.InternalNodeCount++
+
+	fanout := len(node.Children)
+	cMap.levelStats(stats, level).Fanout[fanout]++
+
+	var childSlot CMapChildNode
+	stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*node)) + uint64(cap(node.Children)) * uint64(unsafe.Sizeof(childSlot))
+
+	for _, child := range node.Children {
+		switch childNode := child.(type) {
+			case *CMapNode[T]:
+				cMap.statsRecursive(childNode, level + 1, stats)
+			case *CMapCollision:
+				var leafSlot CMapLeafNode
+				stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*childNode)) + uint64(cap(childNode.Leaves)) * uint64(unsafe.Sizeof(leafSlot))
+				for _, leaf := range childNode.Leaves { cMap.leafStats(leaf, level, stats) }
+			case CMapLeafNode:
+				cMap.leafStats(childNode, level, stats)
+		}
+	}
+}
+
+// leafStats 
+//	Counts a leaf node in the child node array of an internal node.
+//	For inline leaves, the key and value are held within the node, so only the remainder of the node is counted as overhead.
+//	For multimap leaves, the values are the keys of the trie of values, so the nodes of that trie are counted as overhead. Counters are stored natively, so their size is counted as value bytes.
+//
+// Parameters:
+//	leaf: the leaf node being counted
+//	level: the level of the internal node holding the leaf
+//	stats: the statistics being collected
+func (cMap *CMap[T]) leafStats(leaf CMapLeafNode, level int, stats *CMapStats) {
+	cMap.levelStats(stats, level + 1).Leaves++
+	stats.LeafCount++
+	stats.KeyBytes += uint64(len(leaf.Key()))
+	stats.ValueBytes += uint64(len(leaf.Value()))
+
+	switch leafNode := leaf.(type) {
+		case *cMapInlineLeaf:
+			stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*leafNode)) - uint64(leafNode.keyLen) - uint64(leafNode.valueLen)
+		case *CMapLeaf:
+			stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*leafNode))
+		case *cMapExpiringLeaf:
+			stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*leafNode))
+		case *cMapCacheLeaf:
+			stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*leafNode))
+		case *cMapMultiLeaf[T]:
+			values := &CMapStats{ Levels: []CMapLevelStats{} }
+			cMap.statsRecursive(leafNode.values, 0, values)
+
+			stats.ValueBytes += values.KeyBytes
+			stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*leafNode)) + values.NodeOverheadBytes
+		case *cMapCounterLeaf[int64]:
+			stats.ValueBytes += uint64(unsafe.Sizeof(leafNode.value))
+			stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*leafNode)) - uint64(unsafe.Sizeof(leafNode.value))
+		case *cMapCounterLeaf[float64]:
+			stats.ValueBytes += uint64(unsafe.Sizeof(leafNode.value))
+			stats.NodeOverheadBytes += uint64(unsafe.Sizeof(*leafNode)) - uint64(unsafe.Sizeof(leafNode.value))
+	}
+
+	if level + 1 > stats.MaxDepth { stats.MaxDepth = level + 1 }
+	if level >= cMap.HashChunks { stats.ReseededLeaves++ }
+}
+
+// levelStats
+//	Returns the statistics for a level, extending the per level statistics if the level has not been visited yet.
+//
+// Parameters:
+//	stats: the statistics being collected
+//	level: the level to get the statistics for
+//
+// Returns:
+//	A pointer to the statistics for the level
+func (cMap *CMap[T]) levelStats(stats *CMapStats, level int) *CMapLevelStats {
+	for len(stats.Levels) <= level {
+		slots := 1 << cMap.BitChunkSize
+		stats.Levels = append(stats.Levels, CMapLevelStats{ Level: len(stats.Levels), Fanout: make([]int, slots + 1) })
+	}
+
+	return &stats.Levels[level]
+}
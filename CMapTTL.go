@@ -0,0 +1,156 @@
+package cmap
+
+import "context"
+import "math"
+import "sync/atomic"
+import "time"
+
+
+//========================================= CMap TTL
+
+
+// PutWithTTL 
+//	Inserts or updates a key-value pair that expires after the time to live. 
+//	Once expired, Get treats the key as missing. Expired keys are deleted lazily when read, or by SweepExpired. 
+//	A Put on the same key replaces the leaf, removing the expiry.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//	ttl: the time to live of the key-value pair. If 0 or less, the key-value pair does not expire
+//
+// Returns:
+//	truthy on successful completion, or falsey if MaxRetries was exceeded
+func (cMap *CMap[T]) PutWithTTL(key []byte, value []byte, ttl time.Duration) bool {
+	return cMap.PutWithTTLContext(context.Background(), key, value, ttl) == nil
+}
+
+// PutWithTTLContext 
+//	Same as PutWithTTL, but the retry loop is aborted if the context is cancelled.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//	ttl: the time to live of the key-value pair. If 0 or less, the key-value pair does not expire
+//
+// Returns:
+//	nil on successful completion, the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (cMap *CMap[T]) PutWithTTLContext(ctx context.Context, key []byte, value []byte, ttl time.Duration) error {
+	if ttl <= 0 { return cMap.PutContext(ctx, key, value) }
+
+	expiresAt := cMap.expiresAt(ttl)
+	_, err := cMap.update(ctx, PutOp, key, func(CMapLeafNode) (CMapLeafNode, bool) {
+		return &cMapExpiringLeaf{ key: key, value: value, expiresAt: expiresAt }, true
+	})
+
+	return err
+}
+
+// SweepExpired 
+//	Walks the trie from the current root and deletes every expired key. 
+//	Each key is deleted through the normal compare and swap delete path, and only if its leaf is still the same expired leaf, so a concurrent Put on the key is not lost.
+//
+// Returns:
+//	The total expired keys deleted
+func (cMap *CMap[T]) SweepExpired() int {
+	now := cMap.now()
+	expired := []CMapLeafNode{}
+
+	currRoot := (*CMapNode[T])(atomic.LoadPointer(&cMap.Root))
+	cMap.walkLeaves(currRoot, func(leaf CMapLeafNode) bool {
+		if cMap.isExpired(leaf, now) { expired = append(expired, leaf) }
+		return true
+	})
+
+	deleted := 0
+	for _, leaf := range expired {
+		if cMap.deleteExpired(leaf) { deleted++ }
+	}
+
+	return deleted
+}
+
+// StartExpirySweeper 
+//	Starts a background goroutine that runs SweepExpired on an interval.
+//
+// Parameters:
+//	interval: the time between sweeps
+//
+// Returns:
+//	A function that stops the sweeper
+func (cMap *CMap[T]) StartExpirySweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+				case <- done:
+					return
+				case <- ticker.C:
+					cMap.SweepExpired()
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// deleteExpired 
+//	Deletes the key of an expired leaf, only if the leaf for the key is still the same leaf.
+//
+// Parameters:
+//	leaf: the expired leaf
+//
+// Returns:
+//	truthy if the leaf was deleted
+func (cMap *CMap[T]) deleteExpired(leaf CMapLeafNode) bool {
+	change, err := cMap.update(context.Background(), DeleteOp, leaf.Key(), func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		return nil, existing == leaf
+	})
+
+	return err == nil && change.applied
+}
+
+// isExpired 
+//	Determines whether a leaf was inserted with a time to live that has passed.
+//
+// Parameters:
+//	leaf: the leaf to check
+//	now: the current time in unix nanoseconds
+//
+// Returns:
+//	truthy if the leaf has expired
+func (cMap *CMap[T]) isExpired(leaf CMapLeafNode, now int64) bool {
+	expiringLeaf, ok := leaf.(*cMapExpiringLeaf)
+	return ok && expiringLeaf.expiresAt <= now
+}
+
+// expiresAt 
+//	The time a key inserted now with a time to live expires, capped at the largest time so a long time to live does not overflow into the past.
+//
+// Parameters:
+//	ttl: the time to live, greater than 0
+//
+// Returns:
+//	The time the key expires, in unix nanoseconds
+func (cMap *CMap[T]) expiresAt(ttl time.Duration) int64 {
+	now := cMap.now()
+	if int64(ttl) > math.MaxInt64 - now { return math.MaxInt64 }
+
+	return now + int64(ttl)
+}
+
+// now 
+//	Gets the current time from the Clock of the map, or the system clock if not set.
+//
+// Returns:
+//	The current time in unix nanoseconds
+func (cMap *CMap[T]) now() int64 {
+	if cMap.Clock != nil { return cMap.Clock.Now().UnixNano() }
+	return time.Now().UnixNano()
+}
@@ -0,0 +1,220 @@
+package cmap
+
+import "bytes"
+import "unsafe"
+
+
+//========================================= CMap Transform
+
+
+// Filter 
+//	Creates a map with the key-value pairs of the current version that satisfy a predicate.
+//	The trie is rebuilt bottom-up instead of inserting each kept pair, and every subtree where all pairs are kept is shared with this map as is.
+//	Expired keys are left out.
+//
+// Parameters:
+//	pred: determines whether a key-value pair is kept
+//
+// Returns:
+//	The new map
+func (cMap *CMap[T]) Filter(pred func(key []byte, value []byte) bool) *CMap[T] {
+	root := cMap.loadRoot()
+	now := cMap.now()
+
+	node, tally := cMap.transformNodes(&root.CMapNode, func(leaf CMapLeafNode) CMapLeafNode {
+		if cMap.isExpired(leaf, now) || ! pred(leaf.Key(), leaf.Value()) { return nil }
+		return leaf
+	})
+
+	return cMap.derive(node, tally, root.indexed)
+}
+
+// MapValues 
+//	Creates a map with the keys of the current version and the values returned by a function.
+//	Keys keep their slots, so the trie is rebuilt bottom-up in place of each changed leaf, and every subtree where no value changed is shared with this map as is.
+//	Expired keys are left out, and keys with a time to live keep it.
+//
+// Parameters:
+//	fn: returns the new value for a key-value pair
+//
+// Returns:
+//	The new map
+func (cMap *CMap[T]) MapValues(fn func(key []byte, value []byte) []byte) *CMap[T] {
+	root := cMap.loadRoot()
+	now := cMap.now()
+
+	node, tally := cMap.transformNodes(&root.CMapNode, func(leaf CMapLeafNode) CMapLeafNode {
+		if cMap.isExpired(leaf, now) { return nil }
+
+		value := fn(leaf.Key(), leaf.Value())
+		if bytes.Equal(value, leaf.Value()) { return leaf }
+		if expiringLeaf, ok := leaf.(*cMapExpiringLeaf); ok { return &cMapExpiringLeaf{ key: expiringLeaf.key, value: value, expiresAt: expiringLeaf.expiresAt } }
+
+		return cMap.NewLeafNode(leaf.Key(), value)
+	})
+
+	return cMap.derive(node, tally, root.indexed)
+}
+
+// Partition 
+//	Splits the key-value pairs of the current version into two maps by a predicate, with a single call to the predicate for each pair.
+//	Both tries are rebuilt bottom-up together, and every subtree that falls entirely on one side is shared with this map as is.
+//	Expired keys are left out of both.
+//
+// Parameters:
+//	pred: determines the side of a key-value pair
+//
+// Returns:
+//	The map with the pairs that satisfy the predicate, and the map with the rest
+func (cMap *CMap[T]) Partition(pred func(key []byte, value []byte) bool) (*CMap[T], *CMap[T]) {
+	root := cMap.loadRoot()
+	now := cMap.now()
+
+	in, out, inTally, outTally := cMap.partitionNodes(&root.CMapNode, func(leaf CMapLeafNode) cMapSide {
+		if cMap.isExpired(leaf, now) { return sideNone }
+		if pred(leaf.Key(), leaf.Value()) { return sideIn }
+
+		return sideOut
+	})
+
+	return cMap.derive(in, inTally, root.indexed), cMap.derive(out, outTally, root.indexed)
+}
+
+// transformNodes 
+//	Rebuilds a child of a trie bottom-up with each leaf replaced by the leaf returned by a transform, which keeps the key of the leaf.
+//	Internal nodes are rebuilt index by index and reused if no child changed, and leaf and collision nodes are reused if every leaf was returned as is.
+//
+// Parameters:
+//	child: the child to rebuild
+//	transform: returns the leaf itself to keep it, a new leaf for the same key to replace it, or nil to remove it
+//
+// Returns:
+//	The rebuilt child, or nil if no leaves remain, and the tally of the leaves below it
+func (cMap *CMap[T]) transformNodes(child CMapChildNode, transform func(leaf CMapLeafNode) CMapLeafNode) (CMapChildNode, cMapTally) {
+	var tally cMapTally
+
+	if node, ok := child.(*CMapNode[T]); ok {
+		var bitMap T
+		children := make([]CMapChildNode, 0, len(node.Children))
+		changed := false
+		pos := 0
+
+		for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+			if ! IsBitSet(node.Bitmap, index) { continue }
+
+			newChild, childTally := cMap.transformNodes(node.Children[pos], transform)
+			if newChild != node.Children[pos] { changed = true }
+			if newChild != nil {
+				bitMap = SetBit(bitMap, index)
+				children = append(children, newChild)
+				tally.add(childTally)
+			}
+
+			pos++
+		}
+
+		if ! changed { return node, tally }
+		return cMap.nodeFromChildren(bitMap, children), tally
+	}
+
+	leaves := leavesOf(child)
+	kept := make([]CMapLeafNode, 0, len(leaves))
+	changed := false
+
+	for _, leaf := range leaves {
+		newLeaf := transform(leaf)
+		if newLeaf != leaf { changed = true }
+		if newLeaf != nil {
+			kept = append(kept, newLeaf)
+			tally.addLeaf(newLeaf)
+		}
+	}
+
+	if ! changed { return child, tally }
+	return childFromLeaves(kept), tally
+}
+
+// partitionNodes 
+//	Splits a child of a trie bottom-up into the child holding the leaves on one side of a partition and the child holding the rest.
+//	Internal nodes are rebuilt index by index on each side, and a child that falls entirely on one side is reused on that side.
+//
+// Parameters:
+//	child: the child to split
+//	side: determines the side of a leaf, or that the leaf is left out of both
+//
+// Returns:
+//	The child for each side, or nil if no leaves fall on that side, and the tally of the leaves below each
+func (cMap *CMap[T]) partitionNodes(child CMapChildNode, side func(leaf CMapLeafNode) cMapSide) (CMapChildNode, CMapChildNode, cMapTally, cMapTally) {
+	var inTally, outTally cMapTally
+
+	if node, ok := child.(*CMapNode[T]); ok {
+		var inBitMap, outBitMap T
+		inChildren := make([]CMapChildNode, 0, len(node.Children))
+		outChildren := make([]CMapChildNode, 0, len(node.Children))
+		allIn, allOut := true, true
+		pos := 0
+
+		for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+			if ! IsBitSet(node.Bitmap, index) { continue }
+
+			in, out, childInTally, childOutTally := cMap.partitionNodes(node.Children[pos], side)
+			if in != node.Children[pos] { allIn = false }
+			if out != node.Children[pos] { allOut = false }
+			if in != nil {
+				inBitMap = SetBit(inBitMap, index)
+				inChildren = append(inChildren, in)
+				inTally.add(childInTally)
+			}
+
+			if out != nil {
+				outBitMap = SetBit(outBitMap, index)
+				outChildren = append(outChildren, out)
+				outTally.add(childOutTally)
+			}
+
+			pos++
+		}
+
+		if allIn { return node, nil, inTally, outTally }
+		if allOut { return nil, node, inTally, outTally }
+
+		return cMap.nodeFromChildren(inBitMap, inChildren), cMap.nodeFromChildren(outBitMap, outChildren), inTally, outTally
+	}
+
+	leaves := leavesOf(child)
+	var inLeaves, outLeaves []CMapLeafNode
+
+	for _, leaf := range leaves {
+		switch side(leaf) {
+			case sideIn:
+				inLeaves = append(inLeaves, leaf)
+				inTally.addLeaf(leaf)
+			case sideOut:
+				outLeaves = append(outLeaves, leaf)
+				outTally.addLeaf(leaf)
+		}
+	}
+
+	if len(inLeaves) == len(leaves) { return child, nil, inTally, outTally }
+	if len(outLeaves) == len(leaves) { return nil, child, inTally, outTally }
+
+	return childFromLeaves(inLeaves), childFromLeaves(outLeaves), inTally, outTally
+}
+
+// derive 
+//	Creates a map with the configuration of this map and a root built from a rebuilt root node, with an ordered index if this map has one.
+//
+// Parameters:
+//	node: the rebuilt root node, or nil if the map is empty
+//	tally: the leaves and bytes below the root node
+//	indexed: whether to build an ordered index for the new map
+//
+// Returns:
+//	The new map
+func (cMap *CMap[T]) derive(node CMapChildNode, tally cMapTally, indexed bool) *CMap[T] {
+	derived := cMap.newLike()
+	derived.Root = unsafe.Pointer(newRootFromNode[T](node, tally))
+
+	if indexed { derived.EnableOrderedIndex() }
+	return derived
+}
@@ -0,0 +1,264 @@
+package cmap
+
+import "bytes"
+import "unsafe"
+
+
+//========================================= CMap Transient
+
+
+// Transient 
+//	Creates a builder starting from the current version of the map, for a batch of writes from a single goroutine.
+//	Writes to the transient are not visible in the map, and writes to the map are not visible in the transient.
+//
+// Returns:
+//	The transient
+func (cMap *CMap[T]) Transient() *CMapTransient[T] {
+	transient := &CMapTransient[T]{ cMap: cMap, token: &cMapToken{} }
+	transient.root = transient.ownedRoot(cMap.loadRoot())
+
+	return transient
+}
+
+// Persistent 
+//	Freezes the transient into a new concurrent map sharing its nodes, with the configuration of the map the transient was made from.
+//	The transient takes a new ownership token, so it can still be used, but copies every node before modifying it again and the new map is unaffected.
+//
+// Returns:
+//	The concurrent map
+func (transient *CMapTransient[T]) Persistent() *CMap[T] {
+	cMap := transient.cMap.newLike()
+	cMap.Root = unsafe.Pointer(transient.root)
+
+	transient.token = &cMapToken{}
+	return cMap
+}
+
+// Put 
+//	Inserts or updates a key-value pair, modifying the nodes on the path to the key in place if the transient owns them, and copying them into its ownership otherwise.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+func (transient *CMapTransient[T]) Put(key []byte, value []byte) {
+	leaf := transient.cMap.NewLeafNode(key, value)
+	existing := transient.put(transient.ownRoot(), leaf, 0)
+
+	transient.apply(cMapChange{ existing: existing, leaf: leaf, applied: true })
+}
+
+// Delete 
+//	Removes a key-value pair, modifying the nodes on the path to the key in place if the transient owns them, and copying them into its ownership otherwise.
+//
+// Parameters:
+//	key: the key to delete
+//
+// Returns:
+//	truthy if the key was present
+func (transient *CMapTransient[T]) Delete(key []byte) bool {
+	if transient.cMap.getLeafRecursive(&transient.root.CMapNode, key, 0) == nil { return false }
+
+	existing := transient.delete(transient.ownRoot(), key, 0)
+	transient.apply(cMapChange{ existing: existing, applied: true })
+
+	return true
+}
+
+// Get 
+//	Gets the value for a key from the transient. Expired keys are treated as missing.
+//
+// Parameters:
+//	key: the key to look up
+//
+// Returns:
+//	The value for the key, or nil if not present
+func (transient *CMapTransient[T]) Get(key []byte) []byte {
+	leaf := transient.cMap.getLeafRecursive(&transient.root.CMapNode, key, 0)
+	if leaf == nil || transient.cMap.isExpired(leaf, transient.cMap.now()) { return nil }
+
+	return leaf.Value()
+}
+
+// Len 
+//	The total key-value pairs in the transient.
+//
+// Returns:
+//	The number of key-value pairs
+func (transient *CMapTransient[T]) Len() int {
+	return int(transient.root.size)
+}
+
+// put 
+//	Inserts a leaf below an owned node in place.
+//	Internal children on the path are taken into ownership, and new internal nodes created by splitting a leaf are owned from the start.
+//
+// Parameters:
+//	node: the owned node
+//	leaf: the leaf to insert
+//	level: the level of the node within the trie
+//
+// Returns:
+//	The leaf replaced by the new leaf, or nil if the key was not present
+func (transient *CMapTransient[T]) put(node *CMapNode[T], leaf CMapLeafNode, level int) CMapLeafNode {
+	cMap := transient.cMap
+	hash := cMap.CalculateHashForCurrentLevel(leaf.Key(), level)
+	index := cMap.getSparseIndex(hash, level)
+
+	if ! IsBitSet(node.Bitmap, index) {
+		node.Bitmap = SetBit(node.Bitmap, index)
+		pos := cMap.getPosition(node.Bitmap, hash, level)
+
+		node.Children = append(node.Children, nil)
+		copy(node.Children[pos + 1:], node.Children[pos:])
+		node.Children[pos] = leaf
+
+		return nil
+	}
+
+	pos := cMap.getPosition(node.Bitmap, hash, level)
+
+	switch childNode := node.Children[pos].(type) {
+		case *CMapNode[T]:
+			ownedChild := transient.own(childNode)
+			node.Children[pos] = ownedChild
+
+			return transient.put(ownedChild, leaf, level + 1)
+		case *CMapCollision:
+			idx := childNode.find(leaf.Key())
+			node.Children[pos] = childNode.withLeaf(leaf)
+			if idx == -1 { return nil }
+
+			return childNode.Leaves[idx]
+		case CMapLeafNode:
+			if bytes.Equal(childNode.Key(), leaf.Key()) {
+				node.Children[pos] = leaf
+				return childNode
+			}
+
+			split := cMap.splitLeaf(childNode, leaf, level + 1)
+			transient.claim(split)
+			node.Children[pos] = split
+	}
+
+	return nil
+}
+
+// delete 
+//	Removes the leaf for a key below an owned node in place, removing internal children left empty.
+//
+// Parameters:
+//	node: the owned node
+//	key: the key to remove
+//	level: the level of the node within the trie
+//
+// Returns:
+//	The removed leaf, or nil if the key was not present
+func (transient *CMapTransient[T]) delete(node *CMapNode[T], key []byte, level int) CMapLeafNode {
+	cMap := transient.cMap
+	hash := cMap.CalculateHashForCurrentLevel(key, level)
+	index := cMap.getSparseIndex(hash, level)
+	if ! IsBitSet(node.Bitmap, index) { return nil }
+
+	pos := cMap.getPosition(node.Bitmap, hash, level)
+
+	var removed CMapLeafNode
+	switch childNode := node.Children[pos].(type) {
+		case *CMapNode[T]:
+			ownedChild := transient.own(childNode)
+			node.Children[pos] = ownedChild
+
+			removed = transient.delete(ownedChild, key, level + 1)
+			if len(ownedChild.Children) > 0 { return removed }
+		case *CMapCollision:
+			idx := childNode.find(key)
+			if idx == -1 { return nil }
+
+			node.Children[pos] = childNode.withoutLeaf(idx)
+			return childNode.Leaves[idx]
+		case CMapLeafNode:
+			if ! bytes.Equal(childNode.Key(), key) { return nil }
+			removed = childNode
+	}
+
+	node.Bitmap = SetBit(node.Bitmap, index)
+	copy(node.Children[pos:], node.Children[pos + 1:])
+	node.Children[len(node.Children) - 1] = nil
+	node.Children = node.Children[:len(node.Children) - 1]
+
+	return removed
+}
+
+// own 
+//	Gets a node the transient may modify in place, which is the node itself if the transient owns it, or otherwise a copy owned by the transient.
+//
+// Parameters:
+//	node: the node
+//
+// Returns:
+//	The owned node
+func (transient *CMapTransient[T]) own(node *CMapNode[T]) *CMapNode[T] {
+	if node.owner == transient.token { return node }
+
+	nodeCopy := transient.cMap.CopyNode(node)
+	nodeCopy.owner = transient.token
+
+	return nodeCopy
+}
+
+// ownRoot 
+//	Gets the root node for a write, replacing the root with an owned copy if the transient no longer owns it.
+//
+// Returns:
+//	The owned root node
+func (transient *CMapTransient[T]) ownRoot() *CMapNode[T] {
+	if transient.root.owner != transient.token { transient.root = transient.ownedRoot(transient.root) }
+	return &transient.root.CMapNode
+}
+
+// ownedRoot 
+//	Creates a copy of a root owned by the transient.
+//
+// Parameters:
+//	root: the root to copy
+//
+// Returns:
+//	The owned copy
+func (transient *CMapTransient[T]) ownedRoot(root *cMapRoot[T]) *cMapRoot[T] {
+	children := make([]CMapChildNode, len(root.Children))
+	copy(children, root.Children)
+
+	return &cMapRoot[T]{
+		CMapNode: CMapNode[T]{ Bitmap: root.Bitmap, Children: children, owner: transient.token },
+		size: root.size,
+		bytes: root.bytes,
+		seq: root.seq,
+		indexed: root.indexed,
+		index: root.index,
+	}
+}
+
+// claim 
+//	Marks the internal nodes created by splitting a leaf as owned by the transient. The nodes are new, so no other map can reach them.
+//
+// Parameters:
+//	child: the node created by the split
+func (transient *CMapTransient[T]) claim(child CMapChildNode) {
+	node, ok := child.(*CMapNode[T])
+	if ! ok { return }
+
+	node.owner = transient.token
+	for _, grandChild := range node.Children { transient.claim(grandChild) }
+}
+
+// apply 
+//	Adjusts the entry count, byte size, sequence and ordered index of the root for a change.
+//
+// Parameters:
+//	change: the change made to the leaf for a key
+func (transient *CMapTransient[T]) apply(change cMapChange) {
+	sizeDelta, bytesDelta := change.deltas()
+	transient.root.size += sizeDelta
+	transient.root.bytes += bytesDelta
+	transient.root.seq++
+	transient.root.index = transient.root.nextIndex(change)
+}
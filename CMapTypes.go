@@ -1,34 +1,760 @@
 package cmap
 
+import "context"
+import "os"
+import "sync"
+import "sync/atomic"
+import "time"
 import "unsafe"
 
 
+// CMapChildNode 
+//	A node within the child node array of an internal node. 
+//	Implemented by CMapNode for internal nodes, CMapLeaf and the inline leaf for key-value pairs, and CMapCollision for keys whose hashes collide on every level.
+type CMapChildNode interface {
+	isChildNode()
+}
+
+// CMapLeafNode 
+//	A child node that stores a single key-value pair. Leaf nodes are immutable once created.
+//
+// Methods
+//	Key: the key of the key-value pair
+//	Value: the value of the key-value pair
+type CMapLeafNode interface {
+	CMapChildNode
+	Key() []byte
+	Value() []byte
+}
+
 // CMapNode 
-//	Represents a singular node within the hash array mapped trie data structure. Can be either 32 or 64 bits
+//	Represents an internal node within the hash array mapped trie data structure. Can be either 32 or 64 bits
 //
 // Properties
-//	Key: The key associated with a value. Keys are in byte array representation. Keys are only stored within leaf nodes of the hamt
-// 	Value: The value associated with a key, in byte array representation. Values are only stored within leaf nodes
-// 	IsLeaf: flag indicating if the current node is a leaf node or an internal node
-//	Bitmap: a 32 bit sparse index that indicates the location of each hashed key within the array of child nodes. Only stored in internal nodes
-//	Children: an array of child nodes, which are CMapNodes. Location in the array is determined by the sparse index
+//	Bitmap: a 32 bit or 64 bit sparse index that indicates the location of each hashed key within the array of child nodes
+//	Children: an array of child nodes, which are internal, leaf, or collision nodes. Location in the array is determined by the sparse index
+//	owner: the token of the transient that created the node and may modify it in place, or nil for nodes that are never modified
 type CMapNode[T uint32 | uint64] struct {
-	Key	[]byte
-	Value []byte
-	IsLeaf bool
 	Bitmap T
-	Children []*CMapNode[T]
+	Children []CMapChildNode
+	owner *cMapToken
+}
+
+// CMapLeaf 
+//	A leaf node that references the incoming key and value byte arrays. Used for key-value pairs too large to store inline.
+//
+// Properties
+//	key: the key of the key-value pair
+//	value: the value of the key-value pair
+type CMapLeaf struct {
+	key []byte
+	value []byte
+}
+
+// cMapInlineLeaf 
+//	A leaf node for small key-value pairs, where the key and value are copied into a fixed size array within the node. 
+//	The whole leaf is a single 32 byte allocation, and the incoming byte arrays are not kept alive by the trie.
+//
+// Properties
+//	keyLen: the length of the key, stored at the start of data
+//	valueLen: the length of the value, stored directly after the key
+//	data: the key followed by the value
+type cMapInlineLeaf struct {
+	keyLen uint8
+	valueLen uint8
+	data [inlineLeafSize]byte
+}
+
+// cMapExpiringLeaf 
+//	A leaf node for a key-value pair inserted with a time to live. Once expired, the leaf is treated as missing and is removed lazily or by the sweeper.
+//
+// Properties
+//	key: the key of the key-value pair
+//	value: the value of the key-value pair
+//	expiresAt: the time the leaf expires, in unix nanoseconds
+type cMapExpiringLeaf struct {
+	key []byte
+	value []byte
+	expiresAt int64
+}
+
+// cMapCacheLeaf 
+//	A leaf node for a key-value pair within a CMapCache, which records when the key was last accessed for eviction.
+//
+// Properties
+//	key: the key of the key-value pair
+//	value: the value of the key-value pair
+//	accessed: the tick of the cache when the key was last inserted or read
+type cMapCacheLeaf struct {
+	key []byte
+	value []byte
+	accessed atomic.Int64
+}
+
+// CMapCollision 
+//	A node holding leaf nodes whose keys have the same sparse index on every level up to the maximum depth of the trie. 
+//	The leaves are searched linearly.
+//
+// Properties
+//	Leaves: the leaf nodes with colliding keys
+type CMapCollision struct {
+	Leaves []CMapLeafNode
 }
 
 // CMap 
 //	Root of the hash array mapped trie
 //
 // Properties
-//	Root: the root CMapNode within the hash array mapped trie. Stored as a pointer to the location in memory of the root
+//	Root: the root CMapNode within the hash array mapped trie. Stored as a pointer to the location in memory of the root, which also carries the entry count and byte size of the trie
 //	BitChunkSize: the size of each chunk in the 32 bit or 64 bit hash. Example, with a 32 bit hash total size is 2^5, so each chunk will be 5 bits long
 //	HashChunks: the total chunks of the 32 bit or 64 bit hash determining the levels within the hash array mapped trie
+//	Metrics: optional instrumentation for operations on the trie. If nil, operations are not instrumented
+//	Backoff: optional strategy for waiting between retries of Put and Delete after a failed compare and swap. If nil, operations retry immediately
+//	MaxRetries: the maximum number of retries for Put and Delete before the operation fails with ErrMaxRetriesExceeded. If 0, operations retry until completed
+//	Clock: optional source of the current time for expiring keys. If nil, the system clock is used
+//	nodePool: copies discarded by failed compare and swap attempts, reused for later copies
+//	changeLog: the change log of the trie, or nil if not enabled
+//	versions: the recent roots retained for reads at past versions, or nil if not enabled
 type CMap[T uint32 | uint64] struct {
 	Root unsafe.Pointer
 	BitChunkSize int
 	HashChunks int
-}
\ No newline at end of file
+	Metrics Metrics
+	Backoff Backoff
+	MaxRetries int
+	Clock Clock
+	nodePool sync.Pool
+	changeLog atomic.Pointer[cMapChangeLog]
+	versions atomic.Pointer[cMapVersions[T]]
+}
+
+// cMapRoot 
+//	The root of the trie. The root CMapNode is the first field, so a pointer to the root is also a pointer to the root CMapNode. 
+//	Since every mutation publishes a new root, values kept on the root are consistent with the trie below it.
+//
+// Properties
+//	size: the total key-value pairs in the trie
+//	bytes: the total bytes held in keys and values in the trie
+//	seq: the sequence of the root, incremented on each successful mutation
+//	feed: the change feed of the trie, or nil if nothing has subscribed to changes. Once set, it is carried over to every later root
+//	indexed: whether the trie keeps an ordered index. Once set, it is carried over to every later root
+//	index: the root of the ordered index of the leaves in the trie, or nil if empty or not indexed
+type cMapRoot[T uint32 | uint64] struct {
+	CMapNode[T]
+	size int64
+	bytes int64
+	seq uint64
+	feed *cMapFeed
+	indexed bool
+	index *cMapIndexNode
+}
+
+// cMapIndexNode 
+//	A node of the ordered index, a persistent treap ordered by key and heap ordered by a priority derived from the hash of the key. 
+//	Nodes are immutable once published, so changes copy the path from the root of the index to the key, as in the trie.
+//
+// Properties
+//	leaf: the leaf of the key, shared with the trie
+//	priority: the heap priority of the node
+//	left: the subtree with smaller keys
+//	right: the subtree with larger keys
+type cMapIndexNode struct {
+	leaf CMapLeafNode
+	priority uint32
+	left *cMapIndexNode
+	right *cMapIndexNode
+}
+
+// cMapSmallNode 
+//	An internal node allocated together with a fixed size child node array, used for nodes with small fanout.
+type cMapSmallNode[T uint32 | uint64] struct {
+	node CMapNode[T]
+	children [smallFanout]CMapChildNode
+}
+
+// CMapStats
+//	A point in time summary of the structure of the hash array mapped trie, used to compare the 32 bit and 64 bit variants against a dataset.
+//
+// Properties
+//	LeafCount: the total number of leaf nodes, which is the number of key-value pairs in the trie
+//	InternalNodeCount: the total number of internal nodes, including the root
+//	MaxDepth: the depth of the deepest leaf node, where a leaf in the child node array of the root is at depth 1
+//	AverageDepth: the mean depth across all leaf nodes
+//	Levels: per level statistics, indexed by level, where the root is level 0
+//	Reseeds: the number of times the hash was reseeded on the deepest path, so the levels beyond HashChunks divided by HashChunks
+//	ReseededLeaves: the total leaf nodes that were indexed with a reseeded hash
+//	KeyBytes: the total bytes held in keys
+//	ValueBytes: the total bytes held in values
+//	NodeOverheadBytes: the estimated bytes held by the nodes themselves and their child node arrays, excluding keys and values
+type CMapStats struct {
+	LeafCount int
+	InternalNodeCount int
+	MaxDepth int
+	AverageDepth float64
+	Levels []CMapLevelStats
+	Reseeds int
+	ReseededLeaves int
+	KeyBytes uint64
+	ValueBytes uint64
+	NodeOverheadBytes uint64
+}
+
+// CMapLevelStats
+//	The statistics for a single level within the hash array mapped trie.
+//
+// Properties
+//	Level: the level within the trie
+//	InternalNodes: the total internal nodes at the level
+//	Leaves: the total leaf nodes at the level
+//	Fanout: the fanout distribution of internal nodes at the level. The index is the number of children and the value is the total internal nodes with that many children
+type CMapLevelStats struct {
+	Level int
+	InternalNodes int
+	Leaves int
+	Fanout []int
+}
+
+
+// CMapOp 
+//	The operation being instrumented.
+type CMapOp int
+
+const (
+	PutOp CMapOp = iota
+	GetOp
+	DeleteOp
+)
+
+// Metrics 
+//	Optional instrumentation for operations on the hash array mapped trie. 
+//	When set on a CMap, each operation reports its outcome after it completes. Implementations must be safe for concurrent use.
+//
+// Methods
+//	ObserveCASFailure: called each time a compare and swap on the root fails and the operation is retried
+//	ObserveOperation: called once an operation completes with the number of retries it took and the total latency of the operation
+//	ObserveFailure: called instead of ObserveOperation once an operation is aborted, with ErrMaxRetriesExceeded or the context error
+type Metrics interface {
+	ObserveCASFailure(op CMapOp)
+	ObserveOperation(op CMapOp, retries int, latency time.Duration)
+	ObserveFailure(op CMapOp, err error)
+}
+
+// CMapMetrics 
+//	The default Metrics implementation, which keeps lock free counters and fixed bucket histograms for each operation.
+//
+// Properties
+//	Ops: the metrics for each operation, indexed by CMapOp
+type CMapMetrics struct {
+	Ops [3]CMapOpMetrics
+}
+
+// CMapOpMetrics 
+//	The counters and histograms for a single operation.
+//
+// Properties
+//	Count: the total completed operations
+//	RetriesExhausted: the total operations aborted after exceeding MaxRetries
+//	Cancelled: the total operations aborted by their context
+//	CASFailures: the total failed compare and swap operations
+//	Retries: the total retries across all operations
+//	RetryBuckets: cumulative counts of operations that completed within each bound in RetryBucketBounds, with the last bucket being +Inf
+//	LatencyBuckets: cumulative counts of operations that completed within each bound in LatencyBucketBounds, with the last bucket being +Inf
+//	LatencySum: the total latency across all operations in nanoseconds
+type CMapOpMetrics struct {
+	Count atomic.Uint64
+	RetriesExhausted atomic.Uint64
+	Cancelled atomic.Uint64
+	CASFailures atomic.Uint64
+	Retries atomic.Uint64
+	RetryBuckets [len(RetryBucketBounds) + 1]atomic.Uint64
+	LatencyBuckets [len(LatencyBucketBounds) + 1]atomic.Uint64
+	LatencySum atomic.Uint64
+}
+
+
+// Backoff 
+//	A strategy for waiting between retries of an operation after a failed compare and swap, used to reduce contention on hot keys.
+//
+// Methods
+//	Wait: blocks the current goroutine before the next retry. Attempt starts at 1 for the first retry. Returns the context error if the context is cancelled while waiting
+type Backoff interface {
+	Wait(ctx context.Context, attempt int) error
+}
+
+// NoBackoff 
+//	Retries immediately, which is the same as not setting a Backoff strategy.
+type NoBackoff struct {}
+
+// SpinYieldBackoff 
+//	Busy spins for the first attempts, then yields the processor to other goroutines on each subsequent attempt.
+//
+// Properties
+//	Spins: the number of attempts to busy spin before yielding
+//	SpinIterations: the number of loop iterations per spin, doubled on each attempt
+type SpinYieldBackoff struct {
+	Spins int
+	SpinIterations int
+}
+
+// ExponentialBackoff 
+//	Sleeps for an exponentially increasing duration on each attempt, with random jitter so contending goroutines do not retry in lock step.
+//
+// Properties
+//	Base: the duration of the first wait
+//	Max: the upper bound on the duration of any wait
+type ExponentialBackoff struct {
+	Base time.Duration
+	Max time.Duration
+}
+
+// cMapPath 
+//	The path copied by a mutation, kept across retries of the mutation. 
+//	The slices are backed by fixed size buffers for the common case of a shallow trie, so a path is a single allocation.
+//
+// Properties
+//	key: the key being modified
+//	hashes: the hash of the key for each chunk of levels, calculated once per mutation
+//	nodes: the internal nodes on the path to the key, where the node at index i is at level i and the first node is the root
+//	copies: the modified copies of the nodes on the path, where each copy points to the copy at the level below it
+//	change: the change made to the leaf of the key
+//	root: the root published by the successful attempt
+//	guard: if set, checked against the root loaded by each attempt, and the mutation is dropped once it returns falsey
+type cMapPath[T uint32 | uint64] struct {
+	key []byte
+	guard func(root *cMapRoot[T]) bool
+	change cMapChange
+	root *cMapRoot[T]
+	hashes []T
+	nodes []*CMapNode[T]
+	copies []*CMapNode[T]
+	hashesBuf [2]T
+	nodesBuf [8]*CMapNode[T]
+	copiesBuf [8]*CMapNode[T]
+}
+
+// Clock 
+//	A source of the current time, used to determine whether keys have expired. Can be replaced for deterministic tests.
+//
+// Methods
+//	Now: the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// cMapChange 
+//	A change made to the leaf of a key by a mutation.
+//
+// Properties
+//	existing: the leaf before the change, or nil if the key did not exist
+//	leaf: the leaf after the change, or nil if the key was deleted
+//	applied: whether the mutation changed the trie
+type cMapChange struct {
+	existing CMapLeafNode
+	leaf CMapLeafNode
+	applied bool
+}
+
+// CMapCache 
+//	A hash array mapped trie bounded by a maximum number of entries, a maximum number of bytes held in keys and values, or both. 
+//	When a Put takes the cache over capacity, keys are evicted using sampled least recently used eviction until it is back within capacity.
+//
+// Properties
+//	CMap: the underlying hash array mapped trie
+//	MaxEntries: the maximum key-value pairs in the cache. If 0, the entries are not bounded
+//	MaxBytes: the maximum bytes held in keys and values in the cache. If 0, the bytes are not bounded
+//	SampleSize: the number of keys sampled for each eviction, where the least recently used of the sample is evicted. Larger samples approximate true least recently used more closely
+//	OnEvict: optional callback for each evicted key-value pair. Called on the goroutine of the Put that caused the eviction
+//	tick: the logical clock used to order accesses
+//	hits: the total Gets that found the key
+//	misses: the total Gets that did not find the key
+//	evictions: the total evicted key-value pairs
+type CMapCache[T uint32 | uint64] struct {
+	CMap *CMap[T]
+	MaxEntries int
+	MaxBytes int64
+	SampleSize int
+	OnEvict func(key []byte, value []byte)
+	tick atomic.Int64
+	hits atomic.Uint64
+	misses atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// CMapCacheStats 
+//	A point in time summary of the effectiveness of a CMapCache.
+//
+// Properties
+//	Hits: the total Gets that found the key
+//	Misses: the total Gets that did not find the key
+//	Evictions: the total evicted key-value pairs
+//	Entries: the current key-value pairs in the cache
+//	Bytes: the current bytes held in keys and values in the cache
+type CMapCacheStats struct {
+	Hits uint64
+	Misses uint64
+	Evictions uint64
+	Entries int
+	Bytes int64
+}
+
+
+// CMapEventType 
+//	The kind of change to a key.
+type CMapEventType int
+
+const (
+	PutEvent CMapEventType = iota
+	DeleteEvent
+)
+
+// CMapEvent 
+//	A change to a key, published after the compare and swap of the change succeeds.
+//
+// Properties
+//	Type: whether the key was inserted or updated, or deleted
+//	Seq: the sequence of the root the change was published in. Events are delivered in sequence order
+//	Key: the key that changed
+//	OldValue: the value before the change, or nil if the key did not exist
+//	NewValue: the value after the change, or nil if the key was deleted
+type CMapEvent struct {
+	Type CMapEventType
+	Seq uint64
+	Key []byte
+	OldValue []byte
+	NewValue []byte
+}
+
+// SlowConsumerPolicy 
+//	What a subscription does with an event when its buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	DropNewest SlowConsumerPolicy = iota
+	DropOldest
+	Block
+	Disconnect
+)
+
+// CMapWatchOptions 
+//	Options for a subscription to changes.
+//
+// Properties
+//	BufferSize: the number of events buffered for the subscriber. If 0, defaults to 64
+//	Policy: what to do when the buffer is full. DropNewest discards the new event, DropOldest discards the oldest buffered event, 
+//		Block waits for the subscriber and so blocks every writer to the trie, and Disconnect closes the subscription
+type CMapWatchOptions struct {
+	BufferSize int
+	Policy SlowConsumerPolicy
+}
+
+// CMapSubscription 
+//	A subscription to changes, receiving events on a buffered channel.
+//
+// Properties
+//	policy: what to do when the buffer is full
+//	events: the buffered channel of events
+//	done: closed when the subscription is closed, unblocking a blocked send
+//	mu: held while sending, so the events channel is not closed during a send
+//	closed: whether the events channel has been closed
+//	dropped: the total events dropped because the buffer was full
+//	closeOnce: ensures the subscription is only closed once
+//	unsubscribe: removes the subscription from the change feed
+type CMapSubscription struct {
+	policy SlowConsumerPolicy
+	events chan CMapEvent
+	done chan struct{}
+	mu sync.Mutex
+	closed bool
+	dropped atomic.Uint64
+	closeOnce sync.Once
+	unsubscribe func()
+}
+
+// cMapFeed 
+//	The change feed of a trie. Changes are published in the order of the sequence of their root, so events for the same key are always delivered in order.
+//
+// Properties
+//	published: the sequence of the last published change
+//	subscribers: the current subscribers, replaced as a whole when a subscriber is added or removed
+//	subscribeMu: serializes adding and removing subscribers
+//	turnMu: guards waiting for and handing off the turn to publish
+//	turn: signalled when a sequence is published, waking the writers waiting for their turn
+//	waiters: the number of writers waiting for their turn, so publishing without waiters skips the lock
+type cMapFeed struct {
+	published atomic.Uint64
+	subscribers atomic.Pointer[[]*cMapSubscriber]
+	subscribeMu sync.Mutex
+	turnMu sync.Mutex
+	turn sync.Cond
+	waiters atomic.Int64
+}
+
+// cMapSubscriber 
+//	A receiver of events on the change feed.
+//
+// Properties
+//	filter: selects the events for the subscriber. If nil, the subscriber receives every event
+//	deliver: receives each selected event, in sequence order
+type cMapSubscriber struct {
+	filter func(event *CMapEvent) bool
+	deliver func(event CMapEvent)
+}
+
+// CMapChangeLogOptions 
+//	Options for the change log of a trie.
+//
+// Properties
+//	Capacity: the number of most recent changes kept in memory. If 0, defaults to 4096
+//	Path: optional file to append every change to, so changes older than the in memory ring can be replayed. The file is truncated when the log is enabled
+//	Sync: whether to sync the file after each change
+type CMapChangeLogOptions struct {
+	Capacity int
+	Path string
+	Sync bool
+}
+
+// cMapChangeLog 
+//	A log of the changes to a trie, ordered by sequence. 
+//	The most recent changes are kept in a ring, and every change is optionally appended to a file.
+//
+// Properties
+//	mu: guards the ring and the file size
+//	startSeq: changes with a sequence after this one are in the log
+//	dropped: the sequence of the newest change overwritten in the ring, or startSeq if none was, so the ring holds every change after it
+//	ring: the most recent changes, where the oldest change is at head
+//	head: the position of the oldest change in the ring
+//	count: the total changes in the ring
+//	file: the file changes are appended to, or nil if the log is in memory only
+//	fileSize: the bytes of complete records written to the file
+//	sync: whether to sync the file after each change
+//	err: the first error writing to the file
+//	remove: removes the log from the change feed
+type cMapChangeLog struct {
+	mu sync.RWMutex
+	startSeq uint64
+	dropped uint64
+	ring []CMapEvent
+	head int
+	count int
+	file *os.File
+	fileSize int64
+	sync bool
+	err error
+	remove func()
+}
+
+// cMapVersions 
+//	The most recent roots of a trie, retained so the trie can be read as of a past version. 
+//	The root with sequence seq is stored at seq modulo the number of retained roots.
+//
+// Properties
+//	roots: the retained roots
+type cMapVersions[T uint32 | uint64] struct {
+	roots []atomic.Pointer[cMapRoot[T]]
+}
+
+// CMapView 
+//	A read only view of a trie as of a single version. 
+//	The view holds its root, so the version stays readable for as long as the view is referenced, and is released once the view is no longer referenced.
+//
+// Properties
+//	cMap: the trie the view is of
+//	root: the root of the version
+type CMapView[T uint32 | uint64] struct {
+	cMap *CMap[T]
+	root *cMapRoot[T]
+}
+
+// PMap 
+//	An immutable map, where every change returns a new map sharing all unchanged nodes with the map it was made from. 
+//	Since published nodes are never modified, a PMap and a CMap can be converted to each other by sharing the root. The zero value is an empty map.
+//
+// Properties
+//	cMap: the map used for hashing and for its configuration, which is never modified by the PMap, or nil for the zero value
+//	root: the root of the map
+type PMap[T uint32 | uint64] struct {
+	cMap *CMap[T]
+	root *cMapRoot[T]
+}
+
+// CMapTransient 
+//	A builder for a map, used from a single goroutine. 
+//	Nodes created by the transient carry its ownership token and are modified in place, so a batch of writes copies each node on its path at most once instead of on every write. 
+//	Nodes shared with a map, including every node once the transient is made persistent, are copied before being modified.
+//
+// Properties
+//	cMap: the map used for hashing, the clock and the node pool
+//	root: the root being built
+//	token: the ownership token of the nodes the transient may modify in place
+type CMapTransient[T uint32 | uint64] struct {
+	cMap *CMap[T]
+	root *cMapRoot[T]
+	token *cMapToken
+}
+
+// cMapToken 
+//	An ownership token, compared by identity. It is not zero sized, so every token has a distinct address.
+type cMapToken struct {
+	_ byte
+}
+
+// ShardedCMap 
+//	A map split into independent tries, where each key belongs to the shard selected by the top bits of its hash. 
+//	Writes to different shards never contend on the same root.
+//
+// Properties
+//	Shards: the tries of the shards, a power of two in number
+//	shardBits: the number of hash bits selecting the shard
+type ShardedCMap[T uint32 | uint64] struct {
+	Shards []*CMap[T]
+	shardBits int
+}
+
+// CSet 
+//	A concurrent set of keys, stored as the leaves of a trie without values. 
+//	Sets built by Union, Intersection and Difference share every subtree left untouched by the operation with the sets they were built from.
+//
+// Properties
+//	cMap: the trie holding the keys of the set
+type CSet[T uint32 | uint64] struct {
+	cMap *CMap[T]
+}
+
+// CMultiMap 
+//	A concurrent map from each key to a collection of distinct values. 
+//	The values of a key are held by its leaf in a persistent trie of their own, so adding or removing a value copies only the path to it, and the new leaf is published with the same compare and swap as CMap.Put.
+//
+// Properties
+//	cMap: the trie holding the keys of the map
+type CMultiMap[T uint32 | uint64] struct {
+	cMap *CMap[T]
+}
+
+// cMapMultiLeaf 
+//	A leaf holding the values of a key in a CMultiMap. Leaves are immutable, so each change creates a new leaf.
+//
+// Properties
+//	key: the key of the leaf
+//	values: the root of the trie of values, where each value is the key of a leaf without a value
+//	count: the number of values
+type cMapMultiLeaf[T uint32 | uint64] struct {
+	key []byte
+	values *CMapNode[T]
+	count int
+}
+
+// CounterMap 
+//	A concurrent map from keys to numeric counters, for aggregating metrics. 
+//	Counters are stored natively in their leaves rather than encoded as values, and each Add publishes a new leaf with the same compare and swap as CMap.Put.
+//
+// Properties
+//	cMap: the trie holding the counters
+type CounterMap[T uint32 | uint64, V int64 | float64] struct {
+	cMap *CMap[T]
+}
+
+// cMapCounterLeaf 
+//	A leaf holding the counter of a key in a CounterMap.
+//
+// Properties
+//	key: the key of the counter
+//	value: the value of the counter
+type cMapCounterLeaf[V int64 | float64] struct {
+	key []byte
+	value V
+}
+
+// cMapTally 
+//	The entry count and byte size of a group of leaves, used to derive the size of a trie built from other tries without walking the shared subtrees.
+//
+// Properties
+//	size: the number of leaves
+//	bytes: the bytes held in the keys and values of the leaves
+type cMapTally struct {
+	size int64
+	bytes int64
+}
+
+// CMapPutCondition 
+//	The condition on the existing key for a conditional put.
+type CMapPutCondition int
+
+const (
+	Always CMapPutCondition = iota
+	IfAbsent
+	IfPresent
+)
+
+// CMapPutOptions 
+//	Options for a conditional put.
+//
+// Properties
+//	TTL: the time to live of the key-value pair. If 0 or less, the key-value pair does not expire
+//	Condition: whether the put requires the key to be absent, present, or neither. Expired keys are treated as absent
+//	Match: if set, the put also requires the key to be present with a value that satisfies it, checked against the leaf the compare and swap replaces
+type CMapPutOptions struct {
+	TTL time.Duration
+	Condition CMapPutCondition
+	Match func(value []byte) bool
+}
+
+// cMapExportNode 
+//	A node of the trie as rendered by ExportDOT and ExportJSON.
+//
+// Properties
+//	Type: internal, leaf, collision, or truncated for a subtree beyond the depth limit
+//	Level: the level of the node
+//	Bitmap: the bitmap of an internal node in binary, with the highest sparse index first
+//	Seed: the number of times the hash was reseeded to index the children of an internal node
+//	Keys: the abbreviated keys of a leaf or collision node
+//	KeyBytes: the length of the key of a leaf
+//	ValueBytes: the length of the value of a leaf
+//	Leaves: the total leaves below a truncated subtree
+//	Children: the children of an internal node with their sparse indexes
+type cMapExportNode struct {
+	Type string `json:"type"`
+	Level int `json:"level"`
+	Bitmap string `json:"bitmap,omitempty"`
+	Seed int `json:"seed,omitempty"`
+	Keys []string `json:"keys,omitempty"`
+	KeyBytes int `json:"keyBytes,omitempty"`
+	ValueBytes int `json:"valueBytes,omitempty"`
+	Leaves int `json:"leaves,omitempty"`
+	Children []cMapExportEdge `json:"children,omitempty"`
+}
+
+// cMapExportEdge 
+//	An edge from an internal node to a child, labelled with the sparse index of the child.
+//
+// Properties
+//	Index: the sparse index of the child
+//	Node: the child
+type cMapExportEdge struct {
+	Index int `json:"index"`
+	Node *cMapExportNode `json:"node"`
+}
+
+// cMapScan 
+//	The state of a single Scan call, collecting keys from the slot of the cursor onwards.
+//
+// Properties
+//	cursor: the cursor the call started from
+//	count: the number of keys after which the call stops at the next slot boundary
+//	now: the time used to skip expired keys
+//	keys: the keys collected so far
+type cMapScan struct {
+	cursor uint64
+	count int
+	now int64
+	keys [][]byte
+}
+
+// cMapSide 
+//	The side of a partition a leaf falls on.
+type cMapSide int
+
+const (
+	sideNone cMapSide = iota
+	sideIn
+	sideOut
+)
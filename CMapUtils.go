@@ -173,9 +173,9 @@ func IsBitSet[V uint32 | uint64](bitmap V, position int) bool {
 //
 // Returns:
 //	The updated child node array
-func ExtendTable[T uint32 | uint64](orig []*CMapNode[T], bitMap T, pos int, newNode *CMapNode[T]) []*CMapNode[T] {
+func ExtendTable[T uint32 | uint64](orig []CMapChildNode, bitMap T, pos int, newNode CMapChildNode) []CMapChildNode {
 	tableSize := calculateHammingWeight(bitMap)
-	newTable := make([]*CMapNode[T], tableSize)
+	newTable := make([]CMapChildNode, tableSize)
 
 	copy(newTable[:pos], orig[:pos])
 	newTable[pos] = newNode
@@ -195,9 +195,9 @@ func ExtendTable[T uint32 | uint64](orig []*CMapNode[T], bitMap T, pos int, newN
 //
 // Returns:
 //	The updated child node array
-func ShrinkTable[T uint32 | uint64](orig []*CMapNode[T], bitMap T, pos int) []*CMapNode[T] {
+func ShrinkTable[T uint32 | uint64](orig []CMapChildNode, bitMap T, pos int) []CMapChildNode {
 	tableSize := calculateHammingWeight(bitMap)
-	newTable := make([]*CMapNode[T], tableSize)
+	newTable := make([]CMapChildNode, tableSize)
 
 	copy(newTable[:pos], orig[:pos])
 	copy(newTable[pos:], orig[pos + 1:])
@@ -205,6 +205,32 @@ func ShrinkTable[T uint32 | uint64](orig []*CMapNode[T], bitMap T, pos int) []*C
 	return newTable
 }
 
+// walkLeaves 
+//	Visits every leaf node in the trie below a node, depth first in the order of the child node arrays. Leaves within collision nodes are visited as well.
+//
+// Parameters:
+//	node: the internal node to start from
+//	visit: called for each leaf node. Returning falsey stops the walk
+//
+// Returns:
+//	falsey if the walk was stopped
+func (cMap *CMap[T]) walkLeaves(node *CMapNode[T], visit func(leaf CMapLeafNode) bool) bool {
+	for _, child := range node.Children {
+		switch childNode := child.(type) {
+			case *CMapNode[T]:
+				if ! cMap.walkLeaves(childNode, visit) { return false }
+			case *CMapCollision:
+				for _, leaf := range childNode.Leaves {
+					if ! visit(leaf) { return false }
+				}
+			case CMapLeafNode:
+				if ! visit(childNode) { return false }
+		}
+	}
+
+	return true
+}
+
 // Print Children is a debugging function for printing nodes in the hash array mapped trie
 func (cMap *CMap[T]) PrintChildren() {
 	cMap.printChildrenRecursive(&cMap.Root, 0)
@@ -215,11 +241,18 @@ func (cMap *CMap[T]) printChildrenRecursive(node *unsafe.Pointer, level int) {
 	if currNode == nil { return }
 
 	for idx, child := range currNode.Children {
-		if child != nil {
-			fmt.Printf("Level: %d, Index: %d, Key: %s, Value: %v\n", level, idx, child.Key, child.Value)
+		switch childNode := child.(type) {
+			case *CMapNode[T]:
+				fmt.Printf("Level: %d, Index: %d, Bitmap: %b\n", level, idx, childNode.Bitmap)
 
-			childPtr := unsafe.Pointer(child)
-			cMap.printChildrenRecursive(&childPtr, level+1)
+				childPtr := unsafe.Pointer(childNode)
+				cMap.printChildrenRecursive(&childPtr, level + 1)
+			case *CMapCollision:
+				for _, leaf := range childNode.Leaves {
+					fmt.Printf("Level: %d, Index: %d, Key: %s, Value: %v (collision)\n", level, idx, leaf.Key(), leaf.Value())
+				}
+			case CMapLeafNode:
+				fmt.Printf("Level: %d, Index: %d, Key: %s, Value: %v\n", level, idx, childNode.Key(), childNode.Value())
 		}
 	}
 }
\ No newline at end of file
@@ -0,0 +1,197 @@
+package cmap
+
+import "bytes"
+import "context"
+import "errors"
+import "fmt"
+import "io"
+
+
+//========================================= CMap Verify
+
+
+// ErrCorrupt is returned by Verify when the trie breaks one of its structural invariants
+var ErrCorrupt = errors.New("cmap: corrupt trie")
+
+
+// Verify 
+//	Checks the structural invariants of the current version of the trie.
+//
+// Returns:
+//	nil if the trie is valid, otherwise an error wrapping ErrCorrupt describing the first violation found
+func (cMap *CMap[T]) Verify() error {
+	return cMap.View().Verify()
+}
+
+// Verify 
+//	Checks the structural invariants of the trie as of the version of the view:
+//
+//	every internal node has a child for each bit set in its bitmap, and no nil children
+//	every internal node other than the root has at least one child, and is within the maximum depth of the trie
+//	every key is stored at the sparse index of its hash on each level of its path
+//	collision nodes hold at least two leaves with distinct keys
+//	the entry count and byte size on the root match the leaves in the trie
+//	if the trie is indexed, the ordered index holds exactly the leaves in the trie, in key order and heap order
+//
+// Returns:
+//	nil if the trie is valid, otherwise an error wrapping ErrCorrupt describing the first violation found
+func (view *CMapView[T]) Verify() error {
+	var leaves, leafBytes int64
+	path := []int{}
+
+	err := view.cMap.verifyNode(&view.root.CMapNode, 0, path, func(leaf CMapLeafNode) {
+		leaves++
+		leafBytes += int64(len(leaf.Key()) + len(leaf.Value()))
+	})
+
+	if err != nil { return err }
+	if leaves != view.root.size { return fmt.Errorf("%w: root counts %d entries, trie holds %d", ErrCorrupt, view.root.size, leaves) }
+	if leafBytes != view.root.bytes { return fmt.Errorf("%w: root counts %d bytes, trie holds %d", ErrCorrupt, view.root.bytes, leafBytes) }
+	if view.root.indexed { return view.verifyIndex() }
+
+	return nil
+}
+
+// VerifySnapshot 
+//	Checks a snapshot itself, rather than a map read from it.
+//	The header, entries and checksum are validated as in ReadSnapshot, and every entry, including expired ones, is loaded into a trie that is then checked with Verify.
+//	The trie must hold as many keys as the header counts entries, so a snapshot with duplicate keys is rejected, and as many bytes as the entries hold.
+//
+// Parameters:
+//	r: the reader for the snapshot
+//
+// Returns:
+//	The entry count of the snapshot, and nil if the snapshot is valid, ErrInvalidSnapshot if it is malformed, fails its checksum, or does not match its trie, ErrSnapshotBits if the hash width does not match, or the error reading
+func VerifySnapshot[T uint32 | uint64](r io.Reader) (int, error) {
+	cMap := NewCMap[T]()
+	var entries, entryBytes int64
+
+	_, err := readSnapshot[T](r, func(key []byte, value []byte, expiresAt int64) error {
+		entries++
+		entryBytes += int64(len(key) + len(value))
+
+		_, err := cMap.update(context.Background(), PutOp, key, func(CMapLeafNode) (CMapLeafNode, bool) {
+			if expiresAt != 0 { return &cMapExpiringLeaf{ key: key, value: value, expiresAt: expiresAt }, true }
+			return cMap.NewLeafNode(key, value), true
+		})
+
+		return err
+	})
+
+	if err != nil { return 0, err }
+	if int64(cMap.Len()) != entries { return 0, fmt.Errorf("%w: header counts %d entries, snapshot holds %d distinct keys", ErrInvalidSnapshot, entries, cMap.Len()) }
+	if cMap.Bytes() != entryBytes { return 0, fmt.Errorf("%w: entries hold %d bytes, trie holds %d", ErrInvalidSnapshot, entryBytes, cMap.Bytes()) }
+	if err := cMap.Verify(); err != nil { return 0, err }
+
+	return int(entries), nil
+}
+
+// verifyIndex 
+//	Checks that the ordered index is sorted, heap ordered by priority, and holds the same leaves as the trie.
+//
+// Returns:
+//	nil if the index is valid, otherwise an error wrapping ErrCorrupt
+func (view *CMapView[T]) verifyIndex() error {
+	var err error
+	var prev []byte
+	var count int64
+
+	var check func(node *cMapIndexNode) bool
+	check = func(node *cMapIndexNode) bool {
+		if node == nil { return true }
+		if (node.left != nil && node.left.priority > node.priority) || (node.right != nil && node.right.priority > node.priority) {
+			err = fmt.Errorf("%w: ordered index node for key %q is not heap ordered", ErrCorrupt, node.leaf.Key())
+			return false
+		}
+
+		if ! check(node.left) { return false }
+		if count > 0 && bytes.Compare(prev, node.leaf.Key()) >= 0 {
+			err = fmt.Errorf("%w: ordered index key %q follows %q", ErrCorrupt, node.leaf.Key(), prev)
+			return false
+		}
+
+		if view.cMap.getLeafRecursive(&view.root.CMapNode, node.leaf.Key(), 0) != node.leaf {
+			err = fmt.Errorf("%w: ordered index leaf for key %q is not in the trie", ErrCorrupt, node.leaf.Key())
+			return false
+		}
+
+		prev = node.leaf.Key()
+		count++
+
+		return check(node.right)
+	}
+
+	if ! check(view.root.index) { return err }
+	if count != view.root.size { return fmt.Errorf("%w: ordered index holds %d entries, trie holds %d", ErrCorrupt, count, view.root.size) }
+
+	return nil
+}
+
+// verifyNode 
+//	Recursively checks an internal node and the nodes below it.
+//
+// Parameters:
+//	node: the internal node to check
+//	level: the level of the node
+//	path: the sparse indexes of the nodes on the path to this node
+//	visit: called for each leaf in the trie
+//
+// Returns:
+//	nil if the node and the nodes below it are valid, otherwise an error wrapping ErrCorrupt
+func (cMap *CMap[T]) verifyNode(node *CMapNode[T], level int, path []int, visit func(leaf CMapLeafNode)) error {
+	if level > cMap.maxLevel() { return fmt.Errorf("%w: internal node at level %d is beyond the maximum depth", ErrCorrupt, level) }
+	if calculateHammingWeight(node.Bitmap) != len(node.Children) { 
+		return fmt.Errorf("%w: internal node at path %v has %d bits set and %d children", ErrCorrupt, path, calculateHammingWeight(node.Bitmap), len(node.Children)) 
+	}
+
+	if level > 0 && len(node.Children) == 0 { return fmt.Errorf("%w: empty internal node at path %v", ErrCorrupt, path) }
+
+	pos := 0
+	for index := 0; index < 1 << cMap.BitChunkSize; index++ {
+		if ! IsBitSet(node.Bitmap, index) { continue }
+
+		childPath := append(path[:level:level], index)
+		switch childNode := node.Children[pos].(type) {
+			case *CMapNode[T]:
+				if err := cMap.verifyNode(childNode, level + 1, childPath, visit); err != nil { return err }
+			case *CMapCollision:
+				if len(childNode.Leaves) < 2 { return fmt.Errorf("%w: collision node at path %v has %d leaves", ErrCorrupt, childPath, len(childNode.Leaves)) }
+
+				for idx, leaf := range childNode.Leaves {
+					if err := cMap.verifyLeaf(leaf, childPath); err != nil { return err }
+					if childNode.find(leaf.Key()) != idx { return fmt.Errorf("%w: duplicate key %q in collision node at path %v", ErrCorrupt, leaf.Key(), childPath) }
+
+					visit(leaf)
+				}
+			case CMapLeafNode:
+				if err := cMap.verifyLeaf(childNode, childPath); err != nil { return err }
+				visit(childNode)
+			default:
+				return fmt.Errorf("%w: nil child at path %v", ErrCorrupt, childPath)
+		}
+
+		pos++
+	}
+
+	return nil
+}
+
+// verifyLeaf 
+//	Checks that a leaf is stored at the sparse index of the hash of its key on every level of its path.
+//
+// Parameters:
+//	leaf: the leaf to check
+//	path: the sparse indexes of the nodes on the path to the leaf, including the index of the leaf itself
+//
+// Returns:
+//	nil if the leaf is on the path of its key, otherwise an error wrapping ErrCorrupt
+func (cMap *CMap[T]) verifyLeaf(leaf CMapLeafNode, path []int) error {
+	for level, index := range path {
+		if cMap.getSparseIndex(cMap.CalculateHashForCurrentLevel(leaf.Key(), level), level) != index {
+			return fmt.Errorf("%w: key %q at path %v does not hash to index %d on level %d", ErrCorrupt, leaf.Key(), path, index, level)
+		}
+	}
+
+	return nil
+}
+
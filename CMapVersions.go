@@ -0,0 +1,153 @@
+package cmap
+
+import "errors"
+import "sync/atomic"
+
+
+//========================================= CMap Versions
+
+
+// ErrVersionNotRetained is returned when reading a version that is no longer, or was never, retained
+var ErrVersionNotRetained = errors.New("cmap: version not retained")
+
+
+// RetainVersions 
+//	Retains the most recent roots of the trie, so they can be read with GetAt and ViewAt. 
+//	The version of a root is its sequence, as returned by Seq. Since roots are never modified once published, a retained root is a complete trie as of its version. 
+//	Calling RetainVersions again replaces the retained roots.
+//
+// Parameters:
+//	n: the number of most recent versions to retain. If 0 or less, versions are no longer retained
+func (cMap *CMap[T]) RetainVersions(n int) {
+	if n <= 0 {
+		cMap.versions.Store(nil)
+		return
+	}
+
+	cMap.versions.Store(&cMapVersions[T]{ roots: make([]atomic.Pointer[cMapRoot[T]], n) })
+	cMap.retainVersion(cMap.loadRoot())
+}
+
+// GetAt 
+//	Retrieves the value for a key as of a past version.
+//
+// Parameters:
+//	key: the key being searched for
+//	version: the version to read
+//
+// Returns:
+//	The value for the key at the version or nil if non-existent, and ErrVersionNotRetained if the version is not retained
+func (cMap *CMap[T]) GetAt(key []byte, version uint64) ([]byte, error) {
+	view, err := cMap.ViewAt(version)
+	if err != nil { return nil, err }
+
+	return view.Get(key), nil
+}
+
+// ViewAt 
+//	Creates a read only view of the trie as of a past version. The version stays readable through the view even once it is no longer retained.
+//
+// Parameters:
+//	version: the version to view
+//
+// Returns:
+//	The view, and ErrVersionNotRetained if the version is not retained
+func (cMap *CMap[T]) ViewAt(version uint64) (*CMapView[T], error) {
+	root := cMap.rootAt(version)
+	if root == nil { return nil, ErrVersionNotRetained }
+
+	return &CMapView[T]{ cMap: cMap, root: root }, nil
+}
+
+// View 
+//	Creates a read only view of the trie as of the current version.
+//
+// Returns:
+//	The view
+func (cMap *CMap[T]) View() *CMapView[T] {
+	return &CMapView[T]{ cMap: cMap, root: cMap.loadRoot() }
+}
+
+// Version 
+//	The version of the view.
+//
+// Returns:
+//	The version
+func (view *CMapView[T]) Version() uint64 {
+	return view.root.seq
+}
+
+// Get 
+//	Retrieves the value for a key as of the version of the view. Keys that expired by the time of the read are treated as missing.
+//
+// Parameters:
+//	key: the key being searched for
+//
+// Returns:
+//	The value for the key or nil if non-existent
+func (view *CMapView[T]) Get(key []byte) []byte {
+	leaf := view.cMap.getLeafRecursive(&view.root.CMapNode, key, 0)
+	if leaf == nil || view.cMap.isExpired(leaf, view.cMap.now()) { return nil }
+
+	return leaf.Value()
+}
+
+// Len 
+//	The total key-value pairs as of the version of the view.
+//
+// Returns:
+//	The total key-value pairs
+func (view *CMapView[T]) Len() int {
+	return int(view.root.size)
+}
+
+// Range 
+//	Visits every key-value pair as of the version of the view, in trie order.
+//
+// Parameters:
+//	fn: called for each key-value pair. Returning falsey stops the iteration
+func (view *CMapView[T]) Range(fn func(key []byte, value []byte) bool) {
+	now := view.cMap.now()
+	view.cMap.walkLeaves(&view.root.CMapNode, func(leaf CMapLeafNode) bool {
+		if view.cMap.isExpired(leaf, now) { return true }
+		return fn(leaf.Key(), leaf.Value())
+	})
+}
+
+// retainVersion 
+//	Stores a newly published root in the retained roots, replacing the root retained at the same position unless that root is newer.
+//
+// Parameters:
+//	root: the published root
+func (cMap *CMap[T]) retainVersion(root *cMapRoot[T]) {
+	versions := cMap.versions.Load()
+	if versions == nil { return }
+
+	slot := &versions.roots[root.seq % uint64(len(versions.roots))]
+	for {
+		curr := slot.Load()
+		if curr != nil && curr.seq >= root.seq { return }
+		if slot.CompareAndSwap(curr, root) { return }
+	}
+}
+
+// rootAt 
+//	Finds the root for a version, either the current root or a retained root.
+//
+// Parameters:
+//	version: the version to find
+//
+// Returns:
+//	The root, or nil if the version is not retained
+func (cMap *CMap[T]) rootAt(version uint64) *cMapRoot[T] {
+	currRoot := cMap.loadRoot()
+	if currRoot.seq == version { return currRoot }
+
+	versions := cMap.versions.Load()
+	if versions == nil || version > currRoot.seq { return nil }
+
+	root := versions.roots[version % uint64(len(versions.roots))].Load()
+	if root == nil || root.seq != version { return nil }
+
+	return root
+}
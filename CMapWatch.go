@@ -0,0 +1,356 @@
+package cmap
+
+import "bytes"
+import "sync/atomic"
+import "unsafe"
+
+
+//========================================= CMap Watch
+
+
+// defaultWatchBufferSize is the number of events buffered for a subscription when BufferSize is not set
+const defaultWatchBufferSize = 64
+
+
+// Subscribe 
+//	Subscribes to every change to the trie. Events are delivered on the channel of the subscription in the order the changes were published.
+//
+// Parameters:
+//	options: the buffer size and slow consumer policy of the subscription
+//
+// Returns:
+//	The subscription, which must be closed once no longer needed
+func (cMap *CMap[T]) Subscribe(options CMapWatchOptions) *CMapSubscription {
+	return cMap.subscribe(nil, options)
+}
+
+// Watch 
+//	Subscribes to changes to a single key.
+//
+// Parameters:
+//	key: the key to watch
+//	options: the buffer size and slow consumer policy of the subscription
+//
+// Returns:
+//	The subscription, which must be closed once no longer needed
+func (cMap *CMap[T]) Watch(key []byte, options CMapWatchOptions) *CMapSubscription {
+	watched := append([]byte(nil), key...)
+	return cMap.subscribe(func(event *CMapEvent) bool { return bytes.Equal(event.Key, watched) }, options)
+}
+
+// WatchPrefix 
+//	Subscribes to changes to every key that starts with the prefix.
+//
+// Parameters:
+//	prefix: the prefix of the keys to watch
+//	options: the buffer size and slow consumer policy of the subscription
+//
+// Returns:
+//	The subscription, which must be closed once no longer needed
+func (cMap *CMap[T]) WatchPrefix(prefix []byte, options CMapWatchOptions) *CMapSubscription {
+	watched := append([]byte(nil), prefix...)
+	return cMap.subscribe(func(event *CMapEvent) bool { return bytes.HasPrefix(event.Key, watched) }, options)
+}
+
+// OnChange 
+//	Registers a callback for every change to the trie. 
+//	The callback runs on the goroutine of the write that made the change, before the write returns, and in the order the changes were published. 
+//	Writes to the trie wait for the callback of the previous change to return, so the callback must be fast and must not modify the trie.
+//
+// Parameters:
+//	fn: the callback for each change
+//
+// Returns:
+//	A function that removes the callback
+func (cMap *CMap[T]) OnChange(fn func(event CMapEvent)) func() {
+	feed := cMap.changeFeed()
+	subscriber := &cMapSubscriber{ deliver: fn }
+	feed.addSubscriber(subscriber)
+
+	return func() { feed.removeSubscriber(subscriber) }
+}
+
+// subscribe 
+//	Creates a subscription and adds it to the change feed of the trie.
+//
+// Parameters:
+//	filter: selects the events for the subscription. If nil, every event is selected
+//	options: the buffer size and slow consumer policy of the subscription
+//
+// Returns:
+//	The subscription
+func (cMap *CMap[T]) subscribe(filter func(event *CMapEvent) bool, options CMapWatchOptions) *CMapSubscription {
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 { bufferSize = defaultWatchBufferSize }
+
+	feed := cMap.changeFeed()
+	sub := &CMapSubscription{
+		policy: options.Policy,
+		events: make(chan CMapEvent, bufferSize),
+		done: make(chan struct{}),
+	}
+
+	subscriber := &cMapSubscriber{ filter: filter, deliver: sub.send }
+	sub.unsubscribe = func() { feed.removeSubscriber(subscriber) }
+	feed.addSubscriber(subscriber)
+
+	return sub
+}
+
+// changeFeed 
+//	Returns the change feed of the trie, creating it on first use. 
+//	The feed is set by publishing a new root with the same trie, so every root after it carries the feed and every change after it is published to it.
+//
+// Returns:
+//	The change feed
+func (cMap *CMap[T]) changeFeed() *cMapFeed {
+	for {
+		currRoot := cMap.loadRoot()
+		if currRoot.feed != nil { return currRoot.feed }
+
+		feed := newCMapFeed(currRoot.seq + 1)
+
+		newRoot := &cMapRoot[T]{
+			CMapNode: CMapNode[T]{ Bitmap: currRoot.Bitmap, Children: currRoot.Children },
+			size: currRoot.size,
+			bytes: currRoot.bytes,
+			seq: currRoot.seq + 1,
+			feed: feed,
+			indexed: currRoot.indexed,
+			index: currRoot.index,
+		}
+
+		if atomic.CompareAndSwapPointer(&cMap.Root, unsafe.Pointer(currRoot), unsafe.Pointer(newRoot)) { 
+			cMap.retainVersion(newRoot)
+			return feed 
+		}
+	}
+}
+
+// Events 
+//	The channel of events for the subscription. The channel is closed when the subscription is closed.
+//
+// Returns:
+//	The channel of events
+func (sub *CMapSubscription) Events() <-chan CMapEvent {
+	return sub.events
+}
+
+// Dropped 
+//	The total events dropped because the buffer of the subscription was full.
+//
+// Returns:
+//	The total dropped events
+func (sub *CMapSubscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// Close 
+//	Removes the subscription from the change feed and closes the events channel. Safe to call more than once.
+func (sub *CMapSubscription) Close() {
+	sub.closeOnce.Do(func() {
+		sub.unsubscribe()
+		close(sub.done)
+
+		sub.mu.Lock()
+		defer sub.mu.Unlock()
+
+		if ! sub.closed {
+			sub.closed = true
+			close(sub.events)
+		}
+	})
+}
+
+// send 
+//	Delivers an event to the subscription, applying the slow consumer policy if the buffer is full.
+//
+// Parameters:
+//	event: the event to deliver
+func (sub *CMapSubscription) send(event CMapEvent) {
+	disconnect := func() bool {
+		sub.mu.Lock()
+		defer sub.mu.Unlock()
+
+		if sub.closed { return false }
+
+		select {
+			case sub.events <- event:
+				return false
+			default:
+		}
+
+		switch sub.policy {
+			case Block:
+				select {
+					case sub.events <- event:
+					case <- sub.done:
+				}
+			case DropOldest:
+				for {
+					select {
+						case <- sub.events:
+							sub.dropped.Add(1)
+						default:
+					}
+
+					select {
+						case sub.events <- event:
+							return false
+						default:
+					}
+				}
+			case Disconnect:
+				sub.dropped.Add(1)
+				sub.closed = true
+				close(sub.events)
+
+				return true
+			default:
+				sub.dropped.Add(1)
+		}
+
+		return false
+	}()
+
+	if disconnect { sub.Close() }
+}
+
+// newCMapFeed 
+//	Creates a change feed starting after a sequence.
+//
+// Parameters:
+//	seq: the sequence of the root that publishes the feed, which has no change of its own
+//
+// Returns:
+//	The change feed
+func newCMapFeed(seq uint64) *cMapFeed {
+	feed := &cMapFeed{}
+	feed.turn.L = &feed.turnMu
+	feed.published.Store(seq)
+
+	return feed
+}
+
+// publish 
+//	Publishes a change to the subscribers of the feed. 
+//	Waits until the change from the previous root has been published, so changes are delivered in the order of their roots.
+//	The sequence is marked published even if a callback panics, so the writers after it are never blocked by a failed callback.
+//
+// Parameters:
+//	seq: the sequence of the root the change was published in
+//	change: the change to publish
+func (feed *cMapFeed) publish(seq uint64, change cMapChange) {
+	feed.waitTurn(seq)
+	defer feed.endTurn(seq)
+
+	subscribers := feed.subscribers.Load()
+	if subscribers == nil || len(*subscribers) == 0 { return }
+
+	event := change.event(seq)
+	for _, subscriber := range *subscribers {
+		if subscriber.filter == nil || subscriber.filter(&event) { subscriber.deliver(event) }
+	}
+}
+
+// advance 
+//	Marks a sequence published without an event, for roots that bump the sequence without changing a key.
+//	Every root carrying a feed must either publish or advance its sequence, or the writers after it wait forever.
+//
+// Parameters:
+//	seq: the sequence of the root
+func (feed *cMapFeed) advance(seq uint64) {
+	feed.waitTurn(seq)
+	feed.endTurn(seq)
+}
+
+// waitTurn 
+//	Waits until every sequence before a sequence has been published. 
+//	The writer of the previous sequence hands off the turn by signalling the waiting writers, so a waiting writer sleeps instead of spinning.
+//
+// Parameters:
+//	seq: the sequence waiting for its turn
+func (feed *cMapFeed) waitTurn(seq uint64) {
+	if feed.published.Load() == seq - 1 { return }
+
+	feed.turnMu.Lock()
+	defer feed.turnMu.Unlock()
+
+	feed.waiters.Add(1)
+	for feed.published.Load() != seq - 1 { feed.turn.Wait() }
+	feed.waiters.Add(-1)
+}
+
+// endTurn 
+//	Marks a sequence published and wakes the writers waiting for their turn.
+//	Waiters register under the lock before checking the published sequence, so a writer that sees no waiters after storing the sequence cannot miss one.
+//
+// Parameters:
+//	seq: the sequence that was published
+func (feed *cMapFeed) endTurn(seq uint64) {
+	feed.published.Store(seq)
+	if feed.waiters.Load() == 0 { return }
+
+	feed.turnMu.Lock()
+	feed.turn.Broadcast()
+	feed.turnMu.Unlock()
+}
+
+// addSubscriber 
+//	Adds a subscriber to the feed, replacing the subscribers with a copy that includes it.
+//
+// Parameters:
+//	subscriber: the subscriber to add
+func (feed *cMapFeed) addSubscriber(subscriber *cMapSubscriber) {
+	feed.subscribeMu.Lock()
+	defer feed.subscribeMu.Unlock()
+
+	subscribers := []*cMapSubscriber{}
+	if curr := feed.subscribers.Load(); curr != nil { subscribers = append(subscribers, *curr...) }
+
+	subscribers = append(subscribers, subscriber)
+	feed.subscribers.Store(&subscribers)
+}
+
+// removeSubscriber 
+//	Removes a subscriber from the feed, replacing the subscribers with a copy that excludes it.
+//
+// Parameters:
+//	subscriber: the subscriber to remove
+func (feed *cMapFeed) removeSubscriber(subscriber *cMapSubscriber) {
+	feed.subscribeMu.Lock()
+	defer feed.subscribeMu.Unlock()
+
+	curr := feed.subscribers.Load()
+	if curr == nil { return }
+
+	subscribers := []*cMapSubscriber{}
+	for _, existing := range *curr {
+		if existing != subscriber { subscribers = append(subscribers, existing) }
+	}
+
+	feed.subscribers.Store(&subscribers)
+}
+
+// event 
+//	Creates the event for a change.
+//
+// Parameters:
+//	seq: the sequence of the root the change was published in
+//
+// Returns:
+//	The event
+func (change cMapChange) event(seq uint64) CMapEvent {
+	event := CMapEvent{ Type: PutEvent, Seq: seq }
+	if change.existing != nil {
+		event.Key = change.existing.Key()
+		event.OldValue = change.existing.Value()
+	}
+
+	if change.leaf != nil {
+		event.Key = change.leaf.Key()
+		event.NewValue = change.leaf.Value()
+	} else { event.Type = DeleteEvent }
+
+	return event
+}
@@ -0,0 +1,182 @@
+package cmap
+
+import "context"
+
+
+//========================================= CMultiMap
+
+
+// NewCMultiMap 
+//	Creates an empty concurrent multimap.
+//
+// Returns:
+//	The new multimap
+func NewCMultiMap[T uint32 | uint64]() *CMultiMap[T] {
+	return &CMultiMap[T]{ cMap: NewCMap[T]() }
+}
+
+// Add 
+//	Adds a value to the values of a key.
+//	The value is inserted into a copy of the path in the trie of values of the existing leaf, and the new leaf replaces the existing leaf through the same compare and swap on the root as CMap.Put.
+//
+// Parameters:
+//	key: the key
+//	value: the value to add
+//
+// Returns:
+//	truthy if the value was added, or falsey if the key already held the value
+func (multiMap *CMultiMap[T]) Add(key []byte, value []byte) bool {
+	change, err := multiMap.cMap.update(context.Background(), PutOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		values, count := &CMapNode[T]{}, 0
+		if existing != nil {
+			multiLeaf := existing.(*cMapMultiLeaf[T])
+			values, count = multiLeaf.values, multiLeaf.count
+		}
+
+		newValues, existingValue := multiMap.cMap.insertLeaf(values, multiMap.cMap.NewLeafNode(value, nil), 0, false)
+		if existingValue != nil { return nil, false }
+
+		return &cMapMultiLeaf[T]{ key: key, values: newValues.(*CMapNode[T]), count: count + 1 }, true
+	})
+
+	return err == nil && change.applied
+}
+
+// RemoveValue 
+//	Removes a value from the values of a key. The key is removed once it holds no values.
+//
+// Parameters:
+//	key: the key
+//	value: the value to remove
+//
+// Returns:
+//	truthy if the value was removed, or falsey if the key did not hold the value
+func (multiMap *CMultiMap[T]) RemoveValue(key []byte, value []byte) bool {
+	change, err := multiMap.cMap.update(context.Background(), DeleteOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		if existing == nil { return nil, false }
+
+		multiLeaf := existing.(*cMapMultiLeaf[T])
+		newValues, removed := multiMap.cMap.removeLeaf(multiLeaf.values, value, 0)
+		if removed == nil { return nil, false }
+		if newValues == nil { return nil, true }
+
+		return &cMapMultiLeaf[T]{ key: key, values: newValues.(*CMapNode[T]), count: multiLeaf.count - 1 }, true
+	})
+
+	return err == nil && change.applied
+}
+
+// Delete 
+//	Removes a key with all of its values.
+//
+// Parameters:
+//	key: the key to remove
+//
+// Returns:
+//	truthy if the key was removed, or falsey if it was not present
+func (multiMap *CMultiMap[T]) Delete(key []byte) bool {
+	_, existed := multiMap.cMap.LoadAndDelete(key)
+	return existed
+}
+
+// GetAll 
+//	Gets the values of a key, as of a single version, in no particular order.
+//
+// Parameters:
+//	key: the key to look up
+//
+// Returns:
+//	The values of the key, or nil if the key is not present
+func (multiMap *CMultiMap[T]) GetAll(key []byte) [][]byte {
+	multiLeaf := multiMap.leaf(key)
+	if multiLeaf == nil { return nil }
+
+	values := make([][]byte, 0, multiLeaf.count)
+	multiMap.cMap.walkLeaves(multiLeaf.values, func(leaf CMapLeafNode) bool {
+		values = append(values, leaf.Key())
+		return true
+	})
+
+	return values
+}
+
+// Contains 
+//	Determines whether a key holds a value.
+//
+// Parameters:
+//	key: the key to look up
+//	value: the value to look for
+//
+// Returns:
+//	truthy if the key holds the value
+func (multiMap *CMultiMap[T]) Contains(key []byte, value []byte) bool {
+	multiLeaf := multiMap.leaf(key)
+	return multiLeaf != nil && multiMap.cMap.getLeafRecursive(multiLeaf.values, value, 0) != nil
+}
+
+// Count 
+//	The number of values of a key, read from its leaf.
+//
+// Parameters:
+//	key: the key to look up
+//
+// Returns:
+//	The number of values, or 0 if the key is not present
+func (multiMap *CMultiMap[T]) Count(key []byte) int {
+	multiLeaf := multiMap.leaf(key)
+	if multiLeaf == nil { return 0 }
+
+	return multiLeaf.count
+}
+
+// Len 
+//	The number of keys in the multimap, read from the root.
+//
+// Returns:
+//	The number of keys
+func (multiMap *CMultiMap[T]) Len() int {
+	return multiMap.cMap.Len()
+}
+
+// Stats 
+//	Collects structural statistics for the trie of keys. See CMap.Stats. The values of each key count towards ValueBytes, and the tries holding them towards NodeOverheadBytes.
+//
+// Returns:
+//	The statistics for the trie
+func (multiMap *CMultiMap[T]) Stats() *CMapStats {
+	return multiMap.cMap.Stats()
+}
+
+// Range 
+//	Visits every key with its values as of a single version, in trie order.
+//
+// Parameters:
+//	fn: called for each key with its values. Returning falsey stops the iteration
+func (multiMap *CMultiMap[T]) Range(fn func(key []byte, values [][]byte) bool) {
+	multiMap.cMap.walkLeaves(&multiMap.cMap.loadRoot().CMapNode, func(leaf CMapLeafNode) bool {
+		multiLeaf := leaf.(*cMapMultiLeaf[T])
+
+		values := make([][]byte, 0, multiLeaf.count)
+		multiMap.cMap.walkLeaves(multiLeaf.values, func(valueLeaf CMapLeafNode) bool {
+			values = append(values, valueLeaf.Key())
+			return true
+		})
+
+		return fn(multiLeaf.key, values)
+	})
+}
+
+// leaf 
+//	Gets the leaf for a key from the current root.
+//
+// Parameters:
+//	key: the key to look up
+//
+// Returns:
+//	The leaf, or nil if the key is not present
+func (multiMap *CMultiMap[T]) leaf(key []byte) *cMapMultiLeaf[T] {
+	leaf := multiMap.cMap.getLeafRecursive(&multiMap.cMap.loadRoot().CMapNode, key, 0)
+	if leaf == nil { return nil }
+
+	return leaf.(*cMapMultiLeaf[T])
+}
@@ -0,0 +1,157 @@
+package cmap
+
+import "context"
+import "unsafe"
+
+
+//========================================= CSet
+
+
+// NewCSet 
+//	Creates an empty concurrent set.
+//
+// Returns:
+//	The new set
+func NewCSet[T uint32 | uint64]() *CSet[T] {
+	return &CSet[T]{ cMap: NewCMap[T]() }
+}
+
+// Add 
+//	Adds a key to the set. Keys are stored as leaves without values, using the same path copying and compare and swap as CMap.Put.
+//
+// Parameters:
+//	key: the key to add
+//
+// Returns:
+//	truthy if the key was added, or falsey if it was already in the set
+func (set *CSet[T]) Add(key []byte) bool {
+	change, err := set.cMap.update(context.Background(), PutOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		if existing != nil { return nil, false }
+		return set.cMap.NewLeafNode(key, nil), true
+	})
+
+	return err == nil && change.applied
+}
+
+// Remove 
+//	Removes a key from the set.
+//
+// Parameters:
+//	key: the key to remove
+//
+// Returns:
+//	truthy if the key was removed, or falsey if it was not in the set
+func (set *CSet[T]) Remove(key []byte) bool {
+	change, err := set.cMap.update(context.Background(), DeleteOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		return nil, existing != nil
+	})
+
+	return err == nil && change.applied
+}
+
+// Contains 
+//	Determines whether a key is in the set.
+//
+// Parameters:
+//	key: the key to look up
+//
+// Returns:
+//	truthy if the key is in the set
+func (set *CSet[T]) Contains(key []byte) bool {
+	return set.cMap.getLeafRecursive(&set.cMap.loadRoot().CMapNode, key, 0) != nil
+}
+
+// Len 
+//	The number of keys in the set, read from the root.
+//
+// Returns:
+//	The number of keys
+func (set *CSet[T]) Len() int {
+	return set.cMap.Len()
+}
+
+// Range 
+//	Visits every key in the set as of a single version, in trie order.
+//
+// Parameters:
+//	fn: called for each key. Returning falsey stops the iteration
+func (set *CSet[T]) Range(fn func(key []byte) bool) {
+	set.cMap.walkLeaves(&set.cMap.loadRoot().CMapNode, func(leaf CMapLeafNode) bool { return fn(leaf.Key()) })
+}
+
+// Verify 
+//	Checks the structural invariants of the trie of the set. See CMap.Verify.
+//
+// Returns:
+//	nil if the trie is well formed, otherwise ErrCorrupt describing the first broken invariant
+func (set *CSet[T]) Verify() error {
+	return set.cMap.Verify()
+}
+
+// Union 
+//	Creates a set with the keys in either set, from the current version of each.
+//	The tries are merged level by level, so subtrees present in only one set, or shared by both, are reused as is, and only the nodes where both sets hold keys are copied.
+//
+// Parameters:
+//	other: the other set
+//
+// Returns:
+//	The new set
+func (set *CSet[T]) Union(other *CSet[T]) *CSet[T] {
+	root, otherRoot := set.cMap.loadRoot(), other.cMap.loadRoot()
+
+	merged, added := set.cMap.unionNodes(&root.CMapNode, &otherRoot.CMapNode, 0)
+	tally := cMapTally{ size: root.size + added.size, bytes: root.bytes + added.bytes }
+
+	return newCSetFromNode[T](merged, tally)
+}
+
+// Intersection 
+//	Creates a set with the keys in both sets, from the current version of each.
+//	Subtrees shared by both sets are reused as is, and subtrees left unchanged from this set are reused as well.
+//
+// Parameters:
+//	other: the other set
+//
+// Returns:
+//	The new set
+func (set *CSet[T]) Intersection(other *CSet[T]) *CSet[T] {
+	root, otherRoot := set.cMap.loadRoot(), other.cMap.loadRoot()
+
+	merged, tally := set.cMap.intersectNodes(&root.CMapNode, &otherRoot.CMapNode, 0)
+	return newCSetFromNode[T](merged, tally)
+}
+
+// Difference 
+//	Creates a set with the keys in this set that are not in the other set, from the current version of each.
+//	Subtrees of this set with no keys from the other set are reused as is.
+//
+// Parameters:
+//	other: the other set
+//
+// Returns:
+//	The new set
+func (set *CSet[T]) Difference(other *CSet[T]) *CSet[T] {
+	root, otherRoot := set.cMap.loadRoot(), other.cMap.loadRoot()
+
+	merged, removed := set.cMap.differenceNodes(&root.CMapNode, &otherRoot.CMapNode, 0)
+	tally := cMapTally{ size: root.size - removed.size, bytes: root.bytes - removed.bytes }
+
+	return newCSetFromNode[T](merged, tally)
+}
+
+// newCSetFromNode 
+//	Creates a set with a root built from a merged root node.
+//
+// Parameters:
+//	node: the merged root node, or nil if the set is empty
+//	tally: the number of keys and bytes below the root node
+//
+// Returns:
+//	The new set
+func newCSetFromNode[T uint32 | uint64](node CMapChildNode, tally cMapTally) *CSet[T] {
+	set := NewCSet[T]()
+	set.cMap.Root = unsafe.Pointer(newRootFromNode[T](node, tally))
+
+	return set
+}
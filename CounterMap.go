@@ -0,0 +1,136 @@
+package cmap
+
+import "context"
+import "sync/atomic"
+import "unsafe"
+
+
+//========================================= Counter Map
+
+
+// NewCounterMap 
+//	Creates an empty counter map.
+//
+// Returns:
+//	The new counter map
+func NewCounterMap[T uint32 | uint64, V int64 | float64]() *CounterMap[T, V] {
+	return &CounterMap[T, V]{ cMap: NewCMap[T]() }
+}
+
+// Add 
+//	Adds a delta to the counter of a key, creating the counter if the key is not present.
+//
+// Parameters:
+//	key: the key of the counter
+//	delta: the amount to add, which may be negative
+//
+// Returns:
+//	The value of the counter after the add, and truthy on successful completion, or 0 and falsey if MaxRetries was exceeded
+func (counterMap *CounterMap[T, V]) Add(key []byte, delta V) (V, bool) {
+	value, err := counterMap.AddContext(context.Background(), key, delta)
+	return value, err == nil
+}
+
+// AddContext 
+//	Same as Add, but the retry loop is aborted if the context is cancelled.
+//
+// Parameters:
+//	ctx: the context for the operation
+//	key: the key of the counter
+//	delta: the amount to add, which may be negative
+//
+// Returns:
+//	The value of the counter after the add, and nil on successful completion, or 0 and the context error if cancelled, or ErrMaxRetriesExceeded if MaxRetries was exceeded
+func (counterMap *CounterMap[T, V]) AddContext(ctx context.Context, key []byte, delta V) (V, error) {
+	var value V
+	_, err := counterMap.cMap.update(ctx, PutOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		value = delta
+		if existing != nil { value += existing.(*cMapCounterLeaf[V]).value }
+
+		return &cMapCounterLeaf[V]{ key: key, value: value }, true
+	})
+
+	if err != nil { return 0, err }
+	return value, nil
+}
+
+// Get 
+//	Gets the counter of a key.
+//
+// Parameters:
+//	key: the key of the counter
+//
+// Returns:
+//	The value of the counter, or 0 if the key is not present
+func (counterMap *CounterMap[T, V]) Get(key []byte) V {
+	leaf := counterMap.cMap.getLeafRecursive(&counterMap.cMap.loadRoot().CMapNode, key, 0)
+	if leaf == nil { return 0 }
+
+	return leaf.(*cMapCounterLeaf[V]).value
+}
+
+// Reset 
+//	Removes the counter of a key, so the next Add starts from 0.
+//
+// Parameters:
+//	key: the key of the counter
+//
+// Returns:
+//	The value of the counter before it was removed, or 0 if the key was not present
+func (counterMap *CounterMap[T, V]) Reset(key []byte) V {
+	change, err := counterMap.cMap.update(context.Background(), DeleteOp, key, func(existing CMapLeafNode) (CMapLeafNode, bool) {
+		return nil, existing != nil
+	})
+
+	if err != nil || ! change.applied { return 0 }
+	return change.existing.(*cMapCounterLeaf[V]).value
+}
+
+// Len 
+//	The number of counters, read from the root.
+//
+// Returns:
+//	The number of counters
+func (counterMap *CounterMap[T, V]) Len() int {
+	return counterMap.cMap.Len()
+}
+
+// Stats 
+//	Collects structural statistics for the trie of counters. See CMap.Stats. Each counter counts its native size towards ValueBytes.
+//
+// Returns:
+//	The statistics for the trie
+func (counterMap *CounterMap[T, V]) Stats() *CMapStats {
+	return counterMap.cMap.Stats()
+}
+
+// Range 
+//	Visits every counter as of a single version, in trie order.
+//
+// Parameters:
+//	fn: called for each key with its counter. Returning falsey stops the iteration
+func (counterMap *CounterMap[T, V]) Range(fn func(key []byte, value V) bool) {
+	counterMap.cMap.walkLeaves(&counterMap.cMap.loadRoot().CMapNode, func(leaf CMapLeafNode) bool {
+		return fn(leaf.Key(), leaf.(*cMapCounterLeaf[V]).value)
+	})
+}
+
+// Drain 
+//	Atomically swaps in an empty root and returns the counters held by the previous root, for export.
+//	Adds that raced with the drain fail their compare and swap against the empty root and are retried on it, so every add is counted exactly once, either in the drained counters or in the map.
+//
+// Returns:
+//	A counter map holding the drained counters, which is no longer shared with the map
+func (counterMap *CounterMap[T, V]) Drain() *CounterMap[T, V] {
+	for {
+		currRoot := counterMap.cMap.loadRoot()
+		emptyRoot := &cMapRoot[T]{ seq: currRoot.seq + 1, feed: currRoot.feed }
+
+		if atomic.CompareAndSwapPointer(&counterMap.cMap.Root, unsafe.Pointer(currRoot), unsafe.Pointer(emptyRoot)) {
+			drained := NewCounterMap[T, V]()
+			drained.cMap.Root = unsafe.Pointer(&cMapRoot[T]{ CMapNode: currRoot.CMapNode, size: currRoot.size, bytes: currRoot.bytes, seq: currRoot.seq })
+
+			return drained
+		}
+	}
+}
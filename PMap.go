@@ -0,0 +1,163 @@
+package cmap
+
+import "unsafe"
+
+
+//========================================= PMap
+
+
+// NewPMap 
+//	Creates an empty immutable map. The zero value of PMap is also an empty map, with the default configuration.
+//
+// Returns:
+//	The empty map
+func NewPMap[T uint32 | uint64]() PMap[T] {
+	cMap := NewCMap[T]()
+	return PMap[T]{ cMap: cMap, root: cMap.loadRoot() }
+}
+
+// PMap 
+//	Gets the current version of the map as an immutable map, sharing the root. This does not copy any nodes.
+//
+// Returns:
+//	The immutable map
+func (cMap *CMap[T]) PMap() PMap[T] {
+	return PMap[T]{ cMap: cMap, root: cMap.loadRoot() }
+}
+
+// CMap 
+//	Creates a concurrent map starting from the immutable map, sharing the root. This does not copy any nodes, and later changes to the concurrent map path copy as usual, so the immutable map is unaffected.
+//	The concurrent map has the configuration of the map the immutable map was made from.
+//
+// Returns:
+//	The concurrent map
+func (pMap PMap[T]) CMap() *CMap[T] {
+	pMap = pMap.orEmpty()
+
+	cMap := pMap.cMap.newLike()
+	cMap.Root = unsafe.Pointer(&cMapRoot[T]{ 
+		CMapNode: pMap.root.CMapNode,
+		size: pMap.root.size,
+		bytes: pMap.root.bytes,
+		seq: pMap.root.seq,
+		indexed: pMap.root.indexed,
+		index: pMap.root.index,
+	})
+
+	return cMap
+}
+
+// With 
+//	Creates a map with a key-value pair inserted or updated, path copying from the root to the leaf for the key.
+//
+// Parameters:
+//	key: the key in the key-value pair
+//	value: the value in the key-value pair
+//
+// Returns:
+//	The new map, sharing every node off the path to the key
+func (pMap PMap[T]) With(key []byte, value []byte) PMap[T] {
+	pMap = pMap.orEmpty()
+
+	leaf := pMap.cMap.NewLeafNode(key, value)
+	node, existing := pMap.cMap.insertLeaf(&pMap.root.CMapNode, leaf, 0, true)
+
+	return pMap.next(node, cMapChange{ existing: existing, leaf: leaf, applied: true })
+}
+
+// Without 
+//	Creates a map with a key removed, path copying from the root to the leaf for the key.
+//
+// Parameters:
+//	key: the key to remove
+//
+// Returns:
+//	The new map, or the same map if the key is not present
+func (pMap PMap[T]) Without(key []byte) PMap[T] {
+	pMap = pMap.orEmpty()
+
+	node, existing := pMap.cMap.removeLeaf(&pMap.root.CMapNode, key, 0)
+	if existing == nil { return pMap }
+
+	return pMap.next(node, cMapChange{ existing: existing, applied: true })
+}
+
+// Get 
+//	Gets the value for a key. Expired keys are treated as missing.
+//
+// Parameters:
+//	key: the key to look up
+//
+// Returns:
+//	The value for the key, or nil if not present
+func (pMap PMap[T]) Get(key []byte) []byte {
+	return pMap.view().Get(key)
+}
+
+// Len 
+//	The total key-value pairs in the map, read from the root.
+//
+// Returns:
+//	The number of key-value pairs
+func (pMap PMap[T]) Len() int {
+	if pMap.root == nil { return 0 }
+	return int(pMap.root.size)
+}
+
+// Bytes 
+//	The total bytes held in keys and values in the map, read from the root.
+//
+// Returns:
+//	The bytes held in keys and values
+func (pMap PMap[T]) Bytes() int64 {
+	if pMap.root == nil { return 0 }
+	return pMap.root.bytes
+}
+
+// Range 
+//	Visits every key-value pair in trie order.
+//
+// Parameters:
+//	fn: called for each key-value pair. Returning falsey stops the iteration
+func (pMap PMap[T]) Range(fn func(key []byte, value []byte) bool) {
+	pMap.view().Range(fn)
+}
+
+// next 
+//	Creates the map following this one from a modified copy of the root node.
+//
+// Parameters:
+//	node: the modified copy of the root node, or nil if the map is now empty
+//	change: the change made to the leaf for the key
+//
+// Returns:
+//	The new map
+func (pMap PMap[T]) next(node CMapChildNode, change cMapChange) PMap[T] {
+	sizeDelta, bytesDelta := change.deltas()
+	root := &cMapRoot[T]{ 
+		size: pMap.root.size + sizeDelta,
+		bytes: pMap.root.bytes + bytesDelta,
+		seq: pMap.root.seq + 1,
+		indexed: pMap.root.indexed,
+		index: pMap.root.nextIndex(change),
+	}
+
+	if internalNode, ok := node.(*CMapNode[T]); ok {
+		root.Bitmap = internalNode.Bitmap
+		root.Children = internalNode.Children
+	}
+
+	return PMap[T]{ cMap: pMap.cMap, root: root }
+}
+
+// view gets a view of the map, for reads shared with CMapView
+func (pMap PMap[T]) view() *CMapView[T] {
+	pMap = pMap.orEmpty()
+	return &CMapView[T]{ cMap: pMap.cMap, root: pMap.root }
+}
+
+// orEmpty returns the map, or an empty map for the zero value
+func (pMap PMap[T]) orEmpty() PMap[T] {
+	if pMap.cMap == nil { return NewPMap[T]() }
+	return pMap
+}
@@ -0,0 +1,432 @@
+package cmaphttp
+
+import "crypto/sha256"
+import "encoding/base64"
+import "encoding/hex"
+import "encoding/json"
+import "errors"
+import "io"
+import "net/http"
+import "net/url"
+import "strconv"
+import "strings"
+import "time"
+
+import "github.com/sirgallo/cmap"
+import "github.com/sirgallo/cmap/cmapmetrics"
+
+
+//========================================= CMap HTTP Handler
+
+
+// defaultMaxBodyBytes is the largest request body accepted when MaxBodyBytes is not set
+const defaultMaxBodyBytes = 32 << 20
+
+// flushInterval is the number of keys written by a streaming listing between flushes
+const flushInterval = 256
+
+var errInvalidEncoding = errors.New("encoding must be raw or base64")
+
+
+// Handler 
+//	An http.Handler exposing a CMap as a JSON and binary REST API.
+//
+//	GET /keys/{key}: the value for the key as the response body, with its digest as the ETag
+//	PUT /keys/{key}: sets the value for the key to the request body, with an optional ttl query parameter
+//	DELETE /keys/{key}: deletes the key
+//	GET /keys: streams every key-value pair as newline delimited JSON, with optional prefix, limit and values query parameters
+//	POST /batch/get, /batch/put, /batch/delete: reads, writes or deletes many keys in a single JSON request
+//	GET /stats: the size, sequence, structure and metrics of the map as JSON
+//
+//	The encoding query parameter selects how keys and values are represented in paths, bodies and JSON. 
+//	With raw, the default, they are used as is. With base64, they are url safe base64 encoded, for binary keys and values. 
+//	PUT and DELETE accept If-Match with the ETag of the current value, which is checked against the value the compare and swap replaces, 
+//	and PUT accepts If-None-Match: * to only create the key.
+//
+// Properties
+//	CMap: the map being served
+//	MaxBodyBytes: the largest request body accepted. If 0, defaults to 32MB
+type Handler[T uint32 | uint64] struct {
+	CMap *cmap.CMap[T]
+	MaxBodyBytes int64
+}
+
+// entry 
+//	A key-value pair in JSON requests and responses. Value is nil for keys that do not exist.
+type entry struct {
+	Key string `json:"key"`
+	Value *string `json:"value"`
+}
+
+
+// NewHandler 
+//	Creates a handler for a map.
+//
+// Parameters:
+//	cMap: the map to serve
+//
+// Returns:
+//	The new handler
+func NewHandler[T uint32 | uint64](cMap *cmap.CMap[T]) *Handler[T] {
+	return &Handler[T]{ CMap: cMap }
+}
+
+// ServeHTTP routes a request to the endpoint for its path and method
+func (handler *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	encoding := r.URL.Query().Get("encoding")
+	if encoding == "" { encoding = "raw" }
+	if encoding != "raw" && encoding != "base64" {
+		writeError(w, http.StatusBadRequest, errInvalidEncoding)
+		return
+	}
+
+	path := r.URL.EscapedPath()
+	switch {
+		case strings.HasPrefix(path, "/keys/"):
+			key, err := decodePathKey(strings.TrimPrefix(path, "/keys/"), encoding)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			switch r.Method {
+				case http.MethodGet, http.MethodHead:
+					handler.get(w, r, key, encoding)
+				case http.MethodPut:
+					handler.put(w, r, key, encoding)
+				case http.MethodDelete:
+					handler.delete(w, r, key)
+				default:
+					methodNotAllowed(w, "GET, HEAD, PUT, DELETE")
+			}
+		case path == "/keys":
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, "GET")
+				return
+			}
+
+			handler.list(w, r, encoding)
+		case strings.HasPrefix(path, "/batch/"):
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, "POST")
+				return
+			}
+
+			handler.batch(w, r, strings.TrimPrefix(path, "/batch/"), encoding)
+		case path == "/stats":
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, "GET")
+				return
+			}
+
+			handler.stats(w)
+		default:
+			writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// get writes the value for a key, or 304 if it matches If-None-Match
+func (handler *Handler[T]) get(w http.ResponseWriter, r *http.Request, key []byte, encoding string) {
+	value := handler.CMap.Get(key)
+	if value == nil {
+		writeError(w, http.StatusNotFound, errors.New("key not found"))
+		return
+	}
+
+	etag := Digest(value)
+	w.Header().Set("ETag", etag)
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if encoding == "base64" {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, base64.URLEncoding.EncodeToString(value))
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(value)
+	}
+}
+
+// put sets the value for a key, checking If-Match and If-None-Match against the current value
+func (handler *Handler[T]) put(w http.ResponseWriter, r *http.Request, key []byte, encoding string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, handler.maxBodyBytes()))
+	if err != nil {
+		writeError(w, readErrorStatus(err), err)
+		return
+	}
+
+	value, err := decodeValue(string(body), encoding)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	options := cmap.CMapPutOptions{}
+	if ttl := r.URL.Query().Get("ttl"); ttl != "" {
+		options.TTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	ifMatch, ifNoneMatch := r.Header.Get("If-Match"), r.Header.Get("If-None-Match")
+	if ifMatch == "*" { options.Condition = cmap.IfPresent }
+	if ifMatch != "" && ifMatch != "*" {
+		options.Match = func(current []byte) bool { return matchesETag(ifMatch, Digest(current)) }
+	}
+
+	if ifNoneMatch == "*" { options.Condition = cmap.IfAbsent }
+
+	written, err := handler.CMap.PutWithOptions(r.Context(), key, value, options)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	if ! written {
+		writeError(w, http.StatusPreconditionFailed, errors.New("precondition failed"))
+		return
+	}
+
+	w.Header().Set("ETag", Digest(value))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// delete deletes a key, checking If-Match against the current value
+func (handler *Handler[T]) delete(w http.ResponseWriter, r *http.Request, key []byte) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && ifMatch != "*" {
+		current := handler.CMap.Get(key)
+		if current == nil || ! matchesETag(ifMatch, Digest(current)) || ! handler.CMap.CompareAndDelete(key, current) {
+			writeError(w, http.StatusPreconditionFailed, errors.New("precondition failed"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if _, deleted := handler.CMap.LoadAndDelete(key); ! deleted {
+		if ifMatch == "*" {
+			writeError(w, http.StatusPreconditionFailed, errors.New("precondition failed"))
+			return
+		}
+
+		writeError(w, http.StatusNotFound, errors.New("key not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// list streams the key-value pairs of the current version of the map as newline delimited JSON
+func (handler *Handler[T]) list(w http.ResponseWriter, r *http.Request, encoding string) {
+	query := r.URL.Query()
+
+	prefix, err := decodeValue(query.Get("prefix"), encoding)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	limit := -1
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("limit must be a non negative integer"))
+			return
+		}
+	}
+
+	withValues := query.Get("values") != "false"
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	written := 0
+	handler.CMap.View().Range(func(key []byte, value []byte) bool {
+		if limit >= 0 && written >= limit { return false }
+		if ! strings.HasPrefix(string(key), string(prefix)) { return true }
+
+		line := entry{ Key: encodeValue(key, encoding) }
+		if withValues {
+			encoded := encodeValue(value, encoding)
+			line.Value = &encoded
+		}
+
+		if encoder.Encode(line) != nil { return false }
+
+		written++
+		if flusher != nil && written % flushInterval == 0 { flusher.Flush() }
+
+		return r.Context().Err() == nil
+	})
+}
+
+// batch runs a batch get, put or delete from a JSON request
+func (handler *Handler[T]) batch(w http.ResponseWriter, r *http.Request, op string, encoding string) {
+	var request struct {
+		Keys []string `json:"keys"`
+		Entries []entry `json:"entries"`
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, handler.maxBodyBytes())).Decode(&request); err != nil {
+		writeError(w, readErrorStatus(err), err)
+		return
+	}
+
+	keys := make([][]byte, len(request.Keys))
+	for idx, encodedKey := range request.Keys {
+		key, err := decodeValue(encodedKey, encoding)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		keys[idx] = key
+	}
+
+	switch op {
+		case "get":
+			entries := make([]entry, len(keys))
+			for idx, key := range keys {
+				entries[idx].Key = request.Keys[idx]
+				if value := handler.CMap.Get(key); value != nil {
+					encoded := encodeValue(value, encoding)
+					entries[idx].Value = &encoded
+				}
+			}
+
+			writeJSON(w, http.StatusOK, map[string]any{ "entries": entries })
+		case "put":
+			pairs := make([][2][]byte, len(request.Entries))
+			for idx, pair := range request.Entries {
+				if pair.Value == nil {
+					writeError(w, http.StatusBadRequest, errors.New("entries must have a value"))
+					return
+				}
+
+				key, keyErr := decodeValue(pair.Key, encoding)
+				value, valueErr := decodeValue(*pair.Value, encoding)
+				if keyErr != nil || valueErr != nil {
+					writeError(w, http.StatusBadRequest, errInvalidEncoding)
+					return
+				}
+
+				pairs[idx] = [2][]byte{ key, value }
+			}
+
+			written := 0
+			for _, pair := range pairs {
+				if handler.CMap.PutContext(r.Context(), pair[0], pair[1]) == nil { written++ }
+			}
+
+			writeJSON(w, http.StatusOK, map[string]any{ "written": written })
+		case "delete":
+			deleted := 0
+			for _, key := range keys {
+				if _, ok := handler.CMap.LoadAndDelete(key); ok { deleted++ }
+			}
+
+			writeJSON(w, http.StatusOK, map[string]any{ "deleted": deleted })
+		default:
+			writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// stats writes the size, sequence, structure and metrics of the map
+func (handler *Handler[T]) stats(w http.ResponseWriter) {
+	stats := map[string]any{
+		"len": handler.CMap.Len(),
+		"bytes": handler.CMap.Bytes(),
+		"seq": handler.CMap.Seq(),
+		"structure": handler.CMap.Stats(),
+	}
+
+	if metrics, ok := handler.CMap.Metrics.(*cmap.CMapMetrics); ok { stats["metrics"] = cmapmetrics.Snapshot(metrics) }
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// maxBodyBytes returns the largest request body accepted
+func (handler *Handler[T]) maxBodyBytes() int64 {
+	if handler.MaxBodyBytes > 0 { return handler.MaxBodyBytes }
+	return defaultMaxBodyBytes
+}
+
+// Digest 
+//	The ETag for a value, which is the quoted hex encoded sha256 of the value.
+//
+// Parameters:
+//	value: the value to digest
+//
+// Returns:
+//	The ETag
+func Digest(value []byte) string {
+	sum := sha256.Sum256(value)
+	return "\"" + hex.EncodeToString(sum[:]) + "\""
+}
+
+// matchesETag returns whether an If-Match or If-None-Match header lists the ETag, or is *
+func matchesETag(header string, etag string) bool {
+	if header == "" { return false }
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag { return true }
+	}
+
+	return false
+}
+
+// decodePathKey decodes a key from an escaped path segment
+func decodePathKey(escaped string, encoding string) ([]byte, error) {
+	if escaped == "" { return nil, errors.New("key must not be empty") }
+
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil { return nil, err }
+
+	return decodeValue(unescaped, encoding)
+}
+
+// decodeValue decodes a key or value from its representation in the encoding
+func decodeValue(encoded string, encoding string) ([]byte, error) {
+	if encoding == "base64" { return base64.URLEncoding.DecodeString(encoded) }
+	return []byte(encoded), nil
+}
+
+// encodeValue encodes a key or value into its representation in the encoding
+func encodeValue(value []byte, encoding string) string {
+	if encoding == "base64" { return base64.URLEncoding.EncodeToString(value) }
+	return string(value)
+}
+
+// writeJSON writes a JSON response with a status code
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// readErrorStatus is the status for an error reading a request body, 413 if the body is over MaxBodyBytes and 400 otherwise
+func readErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) { return http.StatusRequestEntityTooLarge }
+
+	return http.StatusBadRequest
+}
+
+// writeError writes a JSON error response with a status code
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{ "error": err.Error() })
+}
+
+// methodNotAllowed writes a 405 response listing the allowed methods
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
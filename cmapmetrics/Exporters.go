@@ -0,0 +1,147 @@
+package cmapmetrics
+
+import "bufio"
+import "expvar"
+import "fmt"
+import "io"
+import "net/http"
+import "strconv"
+
+import "github.com/sirgallo/cmap"
+
+
+//========================================= CMap Metrics Exporters
+
+
+// Snapshot 
+//	Creates a point in time copy of the metrics as nested maps, which serializes cleanly to json.
+//
+// Parameters:
+//	metrics: the metrics to copy
+//
+// Returns:
+//	The metrics for each operation, keyed by operation name
+func Snapshot(metrics *cmap.CMapMetrics) map[string]any {
+	snapshot := make(map[string]any)
+
+	for idx := range metrics.Ops {
+		opMetrics := &metrics.Ops[idx]
+
+		retryBuckets := make(map[string]uint64)
+		for bIdx, bound := range cmap.RetryBucketBounds {
+			retryBuckets[strconv.Itoa(bound)] = opMetrics.RetryBuckets[bIdx].Load()
+		}
+
+		retryBuckets["+Inf"] = opMetrics.RetryBuckets[len(cmap.RetryBucketBounds)].Load()
+
+		latencyBuckets := make(map[string]uint64)
+		for bIdx, bound := range cmap.LatencyBucketBounds {
+			latencyBuckets[bound.String()] = opMetrics.LatencyBuckets[bIdx].Load()
+		}
+
+		latencyBuckets["+Inf"] = opMetrics.LatencyBuckets[len(cmap.LatencyBucketBounds)].Load()
+
+		snapshot[cmap.CMapOp(idx).String()] = map[string]any{
+			"count": opMetrics.Count.Load(),
+			"retries_exhausted": opMetrics.RetriesExhausted.Load(),
+			"cancelled": opMetrics.Cancelled.Load(),
+			"cas_failures": opMetrics.CASFailures.Load(),
+			"retries": opMetrics.Retries.Load(),
+			"retry_buckets": retryBuckets,
+			"latency_buckets": latencyBuckets,
+			"latency_sum_ns": opMetrics.LatencySum.Load(),
+		}
+	}
+
+	return snapshot
+}
+
+// PublishExpvar 
+//	Publishes the metrics as an expvar variable, so they are served on /debug/vars alongside the runtime variables. 
+//	The variable is evaluated lazily on each read. Like expvar.Publish, this panics if the name is already registered.
+//
+// Parameters:
+//	name: the name of the expvar variable
+//	metrics: the metrics to publish
+func PublishExpvar(name string, metrics *cmap.CMapMetrics) {
+	expvar.Publish(name, expvar.Func(func() any { return Snapshot(metrics) }))
+}
+
+// WritePrometheus 
+//	Writes the metrics in the Prometheus text exposition format. 
+//	Counters are written per operation and the retry and latency distributions are written as histograms.
+//
+// Parameters:
+//	w: the writer to write the metrics to
+//	namespace: the prefix for each metric name, for example "cmap"
+//	metrics: the metrics to write
+//
+// Returns:
+//	An error if writing failed
+func WritePrometheus(w io.Writer, namespace string, metrics *cmap.CMapMetrics) error {
+	writer := bufio.NewWriter(w)
+
+	writeCounter := func(name string, help string, load func(opMetrics *cmap.CMapOpMetrics) uint64) {
+		fmt.Fprintf(writer, "# HELP %s_%s %s\n", namespace, name, help)
+		fmt.Fprintf(writer, "# TYPE %s_%s counter\n", namespace, name)
+		
+		for idx := range metrics.Ops {
+			fmt.Fprintf(writer, "%s_%s{op=\"%s\"} %d\n", namespace, name, cmap.CMapOp(idx), load(&metrics.Ops[idx]))
+		}
+	}
+
+	writeCounter("operations_total", "Total completed operations.", func(opMetrics *cmap.CMapOpMetrics) uint64 { return opMetrics.Count.Load() })
+	writeCounter("retries_exhausted_total", "Total operations aborted after exceeding the maximum retries.", func(opMetrics *cmap.CMapOpMetrics) uint64 { return opMetrics.RetriesExhausted.Load() })
+	writeCounter("cancelled_total", "Total operations aborted by their context.", func(opMetrics *cmap.CMapOpMetrics) uint64 { return opMetrics.Cancelled.Load() })
+	writeCounter("cas_failures_total", "Total failed compare and swap operations.", func(opMetrics *cmap.CMapOpMetrics) uint64 { return opMetrics.CASFailures.Load() })
+
+	fmt.Fprintf(writer, "# HELP %s_operation_retries Retries per completed operation.\n", namespace)
+	fmt.Fprintf(writer, "# TYPE %s_operation_retries histogram\n", namespace)
+
+	for idx := range metrics.Ops {
+		opMetrics := &metrics.Ops[idx]
+		op := cmap.CMapOp(idx)
+
+		for bIdx, bound := range cmap.RetryBucketBounds {
+			fmt.Fprintf(writer, "%s_operation_retries_bucket{op=\"%s\",le=\"%d\"} %d\n", namespace, op, bound, opMetrics.RetryBuckets[bIdx].Load())
+		}
+
+		fmt.Fprintf(writer, "%s_operation_retries_bucket{op=\"%s\",le=\"+Inf\"} %d\n", namespace, op, opMetrics.RetryBuckets[len(cmap.RetryBucketBounds)].Load())
+		fmt.Fprintf(writer, "%s_operation_retries_sum{op=\"%s\"} %d\n", namespace, op, opMetrics.Retries.Load())
+		fmt.Fprintf(writer, "%s_operation_retries_count{op=\"%s\"} %d\n", namespace, op, opMetrics.Count.Load())
+	}
+
+	fmt.Fprintf(writer, "# HELP %s_operation_latency_seconds Latency per completed operation.\n", namespace)
+	fmt.Fprintf(writer, "# TYPE %s_operation_latency_seconds histogram\n", namespace)
+
+	for idx := range metrics.Ops {
+		opMetrics := &metrics.Ops[idx]
+		op := cmap.CMapOp(idx)
+
+		for bIdx, bound := range cmap.LatencyBucketBounds {
+			fmt.Fprintf(writer, "%s_operation_latency_seconds_bucket{op=\"%s\",le=\"%g\"} %d\n", namespace, op, bound.Seconds(), opMetrics.LatencyBuckets[bIdx].Load())
+		}
+
+		fmt.Fprintf(writer, "%s_operation_latency_seconds_bucket{op=\"%s\",le=\"+Inf\"} %d\n", namespace, op, opMetrics.LatencyBuckets[len(cmap.LatencyBucketBounds)].Load())
+		fmt.Fprintf(writer, "%s_operation_latency_seconds_sum{op=\"%s\"} %g\n", namespace, op, float64(opMetrics.LatencySum.Load()) / 1e9)
+		fmt.Fprintf(writer, "%s_operation_latency_seconds_count{op=\"%s\"} %d\n", namespace, op, opMetrics.Count.Load())
+	}
+
+	return writer.Flush()
+}
+
+// PrometheusHandler 
+//	Creates an http handler that serves the metrics in the Prometheus text exposition format, to be mounted on a scrape endpoint like /metrics.
+//
+// Parameters:
+//	namespace: the prefix for each metric name
+//	metrics: the metrics to serve
+//
+// Returns:
+//	The http handler for the metrics
+func PrometheusHandler(namespace string, metrics *cmap.CMapMetrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WritePrometheus(w, namespace, metrics)
+	})
+}
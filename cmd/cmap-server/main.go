@@ -0,0 +1,37 @@
+package main
+
+import "flag"
+import "log"
+import "os"
+import "os/signal"
+import "syscall"
+import "time"
+
+import "github.com/sirgallo/cmap"
+import "github.com/sirgallo/cmap/resp"
+
+
+// cmap-server serves a CMap over the Redis protocol
+func main() {
+	addr := flag.String("addr", "127.0.0.1:6379", "the address to listen on")
+	sweepInterval := flag.Duration("sweep", time.Second, "the interval between sweeps of expired keys, or 0 to only expire keys lazily on read")
+	flag.Parse()
+
+	cMap := cmap.NewCMap[uint64]()
+	if *sweepInterval > 0 {
+		stop := cMap.StartExpirySweeper(*sweepInterval)
+		defer stop()
+	}
+
+	server := resp.NewServer(cMap)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<- signals
+		server.Close()
+	}()
+
+	log.Printf("cmap-server listening on %s", *addr)
+	if err := server.ListenAndServe(*addr); err != resp.ErrServerClosed { log.Fatal(err) }
+}
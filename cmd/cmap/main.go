@@ -0,0 +1,294 @@
+package main
+
+import "bufio"
+import "encoding/base64"
+import "encoding/json"
+import "errors"
+import "flag"
+import "fmt"
+import "io"
+import "os"
+import "path/filepath"
+import "strings"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+const usage = `usage: cmap <command> [flags] <snapshot> [args]
+
+commands:
+  get <snapshot> <key>                    print the value for a key
+  put [-ttl d] <snapshot> <key> <value>   set the value for a key, creating the snapshot if it does not exist
+  del <snapshot> <key>                    delete a key
+  ls [-prefix p] [-limit n] <snapshot>    list keys
+  stats <snapshot>                        print the size and structure of the map
+  verify <snapshot>                       check the checksum and structural invariants of the snapshot
+  dump [-json] <snapshot>                 print every key-value pair, as tab separated text or newline delimited json
+  load [-bits 32|64] <snapshot>           replace the snapshot with newline delimited json read from stdin, as written by dump -json,
+                                          with the hash width of -bits if given, otherwise of the snapshot it replaces
+
+flags:
+  -base64   keys and values in arguments and output are url safe base64 encoded
+`
+
+// entry is a key-value pair in the newline delimited json written by dump and read by load
+type entry struct {
+	Key string `json:"key"`
+	Value string `json:"value"`
+}
+
+// options are the flags shared by every command
+type options struct {
+	base64 bool
+	ttl time.Duration
+	prefix string
+	limit int
+	json bool
+	bits int
+}
+
+
+// cmap inspects and edits snapshot files written by CMap.WriteSnapshot
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := run(os.Args[1], os.Args[2:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run parses the flags of a command and runs it against a snapshot of either hash width
+func run(command string, args []string, stdin io.Reader, stdout io.Writer) error {
+	opts := options{}
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.BoolVar(&opts.base64, "base64", false, "keys and values are url safe base64 encoded")
+	flags.DurationVar(&opts.ttl, "ttl", 0, "time to live for put")
+	flags.StringVar(&opts.prefix, "prefix", "", "only list keys with the prefix")
+	flags.IntVar(&opts.limit, "limit", -1, "the maximum number of keys to list")
+	flags.BoolVar(&opts.json, "json", false, "dump as newline delimited json")
+	flags.IntVar(&opts.bits, "bits", 64, "the hash width of new snapshots, and of the snapshot written by load")
+	if err := flags.Parse(args); err != nil { return err }
+
+	args = flags.Args()
+	if len(args) < 1 { return errors.New("missing snapshot path\n" + usage) }
+
+	bitsSet := false
+	flags.Visit(func(f *flag.Flag) { bitsSet = bitsSet || f.Name == "bits" })
+
+	bits := opts.bits
+	if command != "load" || ! bitsSet {
+		if file, err := os.Open(args[0]); err == nil {
+			fileBits, err := cmap.SnapshotBits(file)
+			file.Close()
+
+			if err != nil { return err }
+			if bitsSet && fileBits != bits { return fmt.Errorf("-bits %d conflicts with the hash width %d of %s", bits, fileBits, args[0]) }
+
+			bits = fileBits
+		} else if ! errors.Is(err, os.ErrNotExist) { return err }
+	}
+
+	if bits == 32 { return runCommand[uint32](command, args[0], args[1:], opts, stdin, stdout) }
+	if bits == 64 { return runCommand[uint64](command, args[0], args[1:], opts, stdin, stdout) }
+
+	return fmt.Errorf("unsupported hash width %d", bits)
+}
+
+// runCommand runs a command against a snapshot with a known hash width
+func runCommand[T uint32 | uint64](command string, path string, args []string, opts options, stdin io.Reader, stdout io.Writer) error {
+	expectArgs := func(n int) error {
+		if len(args) != n { return fmt.Errorf("%s expects %d arguments after the snapshot", command, n) }
+		return nil
+	}
+
+	switch command {
+		case "get":
+			if err := expectArgs(1); err != nil { return err }
+
+			cMap, err := open[T](path)
+			if err != nil { return err }
+
+			key, err := decode(args[0], opts)
+			if err != nil { return err }
+
+			value := cMap.Get(key)
+			if value == nil { return fmt.Errorf("key not found: %s", args[0]) }
+
+			fmt.Fprintln(stdout, encode(value, opts))
+			return nil
+		case "put":
+			if err := expectArgs(2); err != nil { return err }
+
+			cMap, err := open[T](path)
+			if errors.Is(err, os.ErrNotExist) {
+				cMap, err = cmap.NewCMap[T](), nil
+			}
+
+			if err != nil { return err }
+
+			key, keyErr := decode(args[0], opts)
+			value, valueErr := decode(args[1], opts)
+			if keyErr != nil || valueErr != nil { return errors.Join(keyErr, valueErr) }
+
+			cMap.PutWithTTL(key, value, opts.ttl)
+			return save(cMap, path)
+		case "del":
+			if err := expectArgs(1); err != nil { return err }
+
+			cMap, err := open[T](path)
+			if err != nil { return err }
+
+			key, err := decode(args[0], opts)
+			if err != nil { return err }
+
+			if _, deleted := cMap.LoadAndDelete(key); ! deleted { return fmt.Errorf("key not found: %s", args[0]) }
+			return save(cMap, path)
+		case "ls":
+			if err := expectArgs(0); err != nil { return err }
+
+			cMap, err := open[T](path)
+			if err != nil { return err }
+
+			prefix, err := decode(opts.prefix, opts)
+			if err != nil { return err }
+
+			listed := 0
+			cMap.View().Range(func(key []byte, value []byte) bool {
+				if opts.limit >= 0 && listed >= opts.limit { return false }
+				if ! strings.HasPrefix(string(key), string(prefix)) { return true }
+
+				fmt.Fprintln(stdout, encode(key, opts))
+				listed++
+
+				return true
+			})
+
+			return nil
+		case "stats":
+			if err := expectArgs(0); err != nil { return err }
+
+			cMap, err := open[T](path)
+			if err != nil { return err }
+
+			stats := cMap.Stats()
+			fmt.Fprintf(stdout, "entries: %d\nbytes: %d\nseq: %d\nhash width: %d\n", cMap.Len(), cMap.Bytes(), cMap.Seq(), 1 << cMap.BitChunkSize)
+			fmt.Fprintf(stdout, "internal nodes: %d\nmax depth: %d\naverage depth: %.2f\nreseeds: %d\n", stats.InternalNodeCount, stats.MaxDepth, stats.AverageDepth, stats.Reseeds)
+			fmt.Fprintf(stdout, "key bytes: %d\nvalue bytes: %d\nnode overhead bytes: %d\n", stats.KeyBytes, stats.ValueBytes, stats.NodeOverheadBytes)
+
+			for _, level := range stats.Levels {
+				fmt.Fprintf(stdout, "level %d: %d internal nodes, %d leaves\n", level.Level, level.InternalNodes, level.Leaves)
+			}
+
+			return nil
+		case "verify":
+			if err := expectArgs(0); err != nil { return err }
+
+			file, err := os.Open(path)
+			if err != nil { return err }
+			defer file.Close()
+
+			entries, err := cmap.VerifySnapshot[T](file)
+			if err != nil { return err }
+
+			fmt.Fprintf(stdout, "ok: %d entries\n", entries)
+			return nil
+		case "dump":
+			if err := expectArgs(0); err != nil { return err }
+
+			cMap, err := open[T](path)
+			if err != nil { return err }
+
+			writer := bufio.NewWriter(stdout)
+			encoder := json.NewEncoder(writer)
+
+			var dumpErr error
+			cMap.View().Range(func(key []byte, value []byte) bool {
+				if opts.json {
+					dumpErr = encoder.Encode(entry{ Key: encode(key, opts), Value: encode(value, opts) })
+				} else { _, dumpErr = fmt.Fprintf(writer, "%s\t%s\n", encode(key, opts), encode(value, opts)) }
+
+				return dumpErr == nil
+			})
+
+			if dumpErr != nil { return dumpErr }
+			return writer.Flush()
+		case "load":
+			if err := expectArgs(0); err != nil { return err }
+
+			cMap := cmap.NewCMap[T]()
+			decoder := json.NewDecoder(stdin)
+			for {
+				var line entry
+				if err := decoder.Decode(&line); err == io.EOF {
+					break
+				} else if err != nil { return err }
+
+				key, keyErr := decode(line.Key, opts)
+				value, valueErr := decode(line.Value, opts)
+				if keyErr != nil || valueErr != nil { return errors.Join(keyErr, valueErr) }
+
+				cMap.Put(key, value)
+			}
+
+			return save(cMap, path)
+		default:
+			return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+}
+
+// open reads a snapshot file into a map
+func open[T uint32 | uint64](path string) (*cmap.CMap[T], error) {
+	file, err := os.Open(path)
+	if err != nil { return nil, err }
+	defer file.Close()
+
+	return cmap.ReadSnapshot[T](file)
+}
+
+// save writes a map to a snapshot file, replacing the file atomically by writing to a temporary file in the same directory and renaming it. 
+// The file keeps its mode, and a new file is created with mode 0644
+func save[T uint32 | uint64](cMap *cmap.CMap[T], path string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if ! errors.Is(err, os.ErrNotExist) { return err }
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path) + ".tmp*")
+	if err != nil { return err }
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := cMap.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil { return err }
+	return os.Rename(tmp.Name(), path)
+}
+
+// decode decodes a key or value from an argument or json field
+func decode(value string, opts options) ([]byte, error) {
+	if opts.base64 { return base64.URLEncoding.DecodeString(value) }
+	return []byte(value), nil
+}
+
+// encode encodes a key or value for output
+func encode(value []byte, opts options) string {
+	if opts.base64 { return base64.URLEncoding.EncodeToString(value) }
+	return string(value)
+}
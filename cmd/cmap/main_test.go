@@ -0,0 +1,112 @@
+package main
+
+import "bytes"
+import "os"
+import "path/filepath"
+import "strings"
+import "testing"
+
+
+func TestCLI(t *testing.T) {
+	cli := func(t *testing.T, stdin string, command string, args ...string) (string, error) {
+		t.Helper()
+
+		var stdout bytes.Buffer
+		err := run(command, args, strings.NewReader(stdin), &stdout)
+		return stdout.String(), err
+	}
+
+	t.Run("test put get del", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "map.snap")
+
+		if _, err := cli(t, "", "put", path, "hello", "world"); err != nil { t.Fatal(err) }
+		if _, err := cli(t, "", "put", path, "other", "value"); err != nil { t.Fatal(err) }
+
+		out, err := cli(t, "", "get", path, "hello")
+		if err != nil || out != "world\n" { t.Errorf("unexpected get: %q, %v", out, err) }
+
+		if _, err := cli(t, "", "del", path, "hello"); err != nil { t.Fatal(err) }
+		if _, err := cli(t, "", "get", path, "hello"); err == nil { t.Error("expected key not found after delete") }
+		if _, err := cli(t, "", "del", path, "hello"); err == nil { t.Error("expected key not found for a missing key") }
+
+		out, err = cli(t, "", "verify", path)
+		if err != nil || out != "ok: 1 entries\n" { t.Errorf("unexpected verify: %q, %v", out, err) }
+	})
+
+	t.Run("test ls dump load", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "map.snap")
+		for _, key := range []string{ "user:1", "user:2", "order:1" } {
+			if _, err := cli(t, "", "put", "-bits", "32", path, key, "value-" + key); err != nil { t.Fatal(err) }
+		}
+
+		out, err := cli(t, "", "ls", "-prefix", "user:", path)
+		if err != nil || strings.Count(out, "\n") != 2 || strings.Contains(out, "order") { t.Errorf("unexpected ls: %q, %v", out, err) }
+
+		out, err = cli(t, "", "ls", "-limit", "1", path)
+		if err != nil || strings.Count(out, "\n") != 1 { t.Errorf("unexpected limited ls: %q, %v", out, err) }
+
+		dump, err := cli(t, "", "dump", "-json", path)
+		if err != nil || strings.Count(dump, "\n") != 3 { t.Fatalf("unexpected dump: %q, %v", dump, err) }
+
+		copyPath := filepath.Join(dir, "copy.snap")
+		if _, err := cli(t, dump, "load", "-bits", "32", copyPath); err != nil { t.Fatal(err) }
+
+		out, err = cli(t, "", "get", copyPath, "order:1")
+		if err != nil || out != "value-order:1\n" { t.Errorf("unexpected get from loaded snapshot: %q, %v", out, err) }
+
+		out, err = cli(t, "", "stats", copyPath)
+		if err != nil || ! strings.Contains(out, "entries: 3\n") || ! strings.Contains(out, "hash width: 32\n") { t.Errorf("unexpected stats: %q, %v", out, err) }
+	})
+
+	t.Run("test base64", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "map.snap")
+
+		if _, err := cli(t, "", "put", "-base64", path, "AAH_", "AgM="); err != nil { t.Fatal(err) }
+
+		out, err := cli(t, "", "get", "-base64", path, "AAH_")
+		if err != nil || out != "AgM=\n" { t.Errorf("unexpected get: %q, %v", out, err) }
+
+		if _, err := cli(t, "", "put", "-base64", path, "not base64!", "AgM="); err == nil { t.Error("expected a decode error") }
+	})
+
+	t.Run("test bits and mode of existing snapshots", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "map.snap")
+
+		if _, err := cli(t, "", "put", "-bits", "32", path, "key", "value"); err != nil { t.Fatal(err) }
+		if _, err := cli(t, "", "put", "-bits", "64", path, "key", "value"); err == nil { t.Error("expected an error for a conflicting hash width") }
+		if err := os.Chmod(path, 0640); err != nil { t.Fatal(err) }
+
+		dump, err := cli(t, "", "dump", "-json", path)
+		if err != nil { t.Fatal(err) }
+		if _, err := cli(t, dump, "load", "-bits", "64", path); err != nil { t.Fatal(err) }
+
+		out, err := cli(t, "", "stats", path)
+		if err != nil || ! strings.Contains(out, "hash width: 64\n") || ! strings.Contains(out, "entries: 1\n") { t.Errorf("expected load to honor -bits: %q, %v", out, err) }
+
+		if _, err := cli(t, "", "put", path, "other", "value"); err != nil { t.Fatal(err) }
+
+		info, err := os.Stat(path)
+		if err != nil || info.Mode().Perm() != 0640 { t.Errorf("expected the snapshot to keep its mode, got %v, %v", info.Mode().Perm(), err) }
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "map.snap")
+
+		if _, err := cli(t, "", "get", path, "key"); err == nil { t.Error("expected an error for a missing snapshot") }
+		if _, err := cli(t, "", "bogus", path); err == nil { t.Error("expected an error for an unknown command") }
+		if _, err := cli(t, "", "get"); err == nil { t.Error("expected an error for a missing snapshot path") }
+		if _, err := cli(t, "", "put", "-bits", "16", path, "key", "value"); err == nil { t.Error("expected an error for an unsupported hash width") }
+
+		if _, err := cli(t, "", "put", path, "key", "value"); err != nil { t.Fatal(err) }
+		if _, err := cli(t, "", "get", path); err == nil { t.Error("expected an error for missing arguments") }
+
+		snapshot, err := os.ReadFile(path)
+		if err != nil { t.Fatal(err) }
+
+		snapshot[len(snapshot) - 1] ^= 0xff
+		if err := os.WriteFile(path, snapshot, 0644); err != nil { t.Fatal(err) }
+		if _, err := cli(t, "", "verify", path); err == nil { t.Error("expected an error for a corrupt snapshot") }
+	})
+}
@@ -0,0 +1,159 @@
+package resp
+
+import "bufio"
+import "net"
+import "strconv"
+
+
+//========================================= RESP Client
+
+
+// Client 
+//	A minimal RESP client for a single connection, which understands both RESP2 and RESP3 replies. Not safe for concurrent use.
+//
+// Properties
+//	conn: the connection to the server
+//	reader: the buffered reader for the connection
+//	writer: the buffered writer for the connection
+type Client struct {
+	conn net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// Error 
+//	An error reply from the server.
+type Error string
+
+// Error returns the error message, starting with the error code
+func (err Error) Error() string {
+	return string(err)
+}
+
+
+// Dial 
+//	Connects to a RESP server over TCP.
+//
+// Parameters:
+//	addr: the address of the server
+//
+// Returns:
+//	The client, and the error connecting
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil { return nil, err }
+
+	return &Client{ conn: conn, reader: bufio.NewReader(conn), writer: bufio.NewWriter(conn) }, nil
+}
+
+// Do 
+//	Sends a command and reads its reply. 
+//	Simple strings are returned as string, bulk strings as []byte, integers as int64, arrays as []any, maps as map[string]any, and null as nil. 
+//	Error replies are returned as an Error.
+//
+// Parameters:
+//	args: the command name followed by its arguments
+//
+// Returns:
+//	The reply, and the error sending the command or reading the reply
+func (client *Client) Do(args ...string) (any, error) {
+	client.writer.WriteByte('*')
+	client.writer.WriteString(strconv.Itoa(len(args)))
+	client.writer.WriteString("\r\n")
+	for _, arg := range args {
+		client.writer.WriteByte('$')
+		client.writer.WriteString(strconv.Itoa(len(arg)))
+		client.writer.WriteString("\r\n")
+		client.writer.WriteString(arg)
+		client.writer.WriteString("\r\n")
+	}
+
+	if err := client.writer.Flush(); err != nil { return nil, err }
+
+	reply, err := client.readReply()
+	if err != nil { return nil, err }
+	if replyErr, ok := reply.(Error); ok { return nil, replyErr }
+
+	return reply, nil
+}
+
+// Close closes the connection to the server
+func (client *Client) Close() error {
+	return client.conn.Close()
+}
+
+// readReply 
+//	Reads a single reply, recursively reading the elements of aggregate replies.
+//
+// Returns:
+//	The reply, and the error reading it
+func (client *Client) readReply() (any, error) {
+	prefix, err := client.reader.ReadByte()
+	if err != nil { return nil, err }
+
+	switch prefix {
+		case '+', '-', ',', '#', '_':
+			line, lineErr := readLine(client.reader)
+			if lineErr != nil { return nil, lineErr }
+
+			switch prefix {
+				case '+':
+					return string(line), nil
+				case '-':
+					return Error(line), nil
+				case ',':
+					return strconv.ParseFloat(string(line), 64)
+				case '#':
+					return string(line) == "t", nil
+				default:
+					return nil, nil
+			}
+		case ':':
+			return readInt(client.reader)
+		case '$':
+			bulk, bulkErr := readBulk(client.reader)
+			if bulk == nil || bulkErr != nil { return nil, bulkErr }
+
+			return bulk, nil
+		case '*', '%':
+			count, countErr := readInt(client.reader)
+			if countErr != nil { return nil, countErr }
+			if count == -1 { return nil, nil }
+
+			if prefix == '*' {
+				elements := make([]any, count)
+				for idx := range elements {
+					if elements[idx], err = client.readReply(); err != nil { return nil, err }
+				}
+
+				return elements, nil
+			}
+
+			pairs := make(map[string]any, count)
+			for range make([]struct{}, count) {
+				key, keyErr := client.readReply()
+				if keyErr != nil { return nil, keyErr }
+
+				value, valueErr := client.readReply()
+				if valueErr != nil { return nil, valueErr }
+
+				pairs[replyString(key)] = value
+			}
+
+			return pairs, nil
+	}
+
+	return nil, ErrProtocol
+}
+
+// replyString converts a simple or bulk string reply to a string
+func replyString(reply any) string {
+	switch value := reply.(type) {
+		case string:
+			return value
+		case []byte:
+			return string(value)
+	}
+
+	return ""
+}
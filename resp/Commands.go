@@ -0,0 +1,255 @@
+package resp
+
+import "context"
+import "math"
+import "strconv"
+import "strings"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+//========================================= RESP Commands
+
+
+// defaultScanCount is the number of keys visited by SCAN when COUNT is not given
+const defaultScanCount = 10
+
+
+// command 
+//	A command supported by the server.
+//
+// Properties
+//	minArgs: the minimum length of the request, including the command name
+//	maxArgs: the maximum length of the request, including the command name, or -1 if unbounded
+//	run: runs the command with its arguments and writes the reply
+type command struct {
+	minArgs int
+	maxArgs int
+	run func(server *Server, c *conn, args [][]byte)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"ping": { 1, 2, ping },
+		"echo": { 2, 2, echo },
+		"hello": { 1, -1, hello },
+		"quit": { 1, 1, quit },
+		"get": { 2, 2, get },
+		"set": { 3, -1, set },
+		"del": { 2, -1, del },
+		"exists": { 2, -1, exists },
+		"dbsize": { 1, 1, dbsize },
+		"scan": { 2, -1, scan },
+		"mget": { 2, -1, mget },
+		"mset": { 3, -1, mset },
+	}
+}
+
+
+// ping replies PONG, or echoes its argument
+func ping(server *Server, c *conn, args [][]byte) {
+	if len(args) == 1 {
+		c.w.bulk(args[0])
+	} else { c.w.simple("PONG") }
+}
+
+// echo replies with its argument
+func echo(server *Server, c *conn, args [][]byte) {
+	c.w.bulk(args[0])
+}
+
+// hello 
+//	Switches the protocol version of the connection and replies with information about the server. 
+//	HELLO [protover [AUTH username password] [SETNAME clientname]]
+func hello(server *Server, c *conn, args [][]byte) {
+	if len(args) > 0 {
+		proto, err := strconv.Atoi(string(args[0]))
+		if err != nil {
+			c.w.error("ERR Protocol version is not an integer or out of range")
+			return
+		}
+
+		if proto != 2 && proto != 3 {
+			c.w.error("NOPROTO unsupported protocol version")
+			return
+		}
+
+		c.w.proto = proto
+	}
+
+	c.w.mapHeader(5)
+	c.w.bulk([]byte("server"))
+	c.w.bulk([]byte("cmap"))
+	c.w.bulk([]byte("proto"))
+	c.w.integer(int64(c.w.proto))
+	c.w.bulk([]byte("mode"))
+	c.w.bulk([]byte("standalone"))
+	c.w.bulk([]byte("role"))
+	c.w.bulk([]byte("master"))
+	c.w.bulk([]byte("modules"))
+	c.w.array(0)
+}
+
+// quit replies OK and closes the connection
+func quit(server *Server, c *conn, args [][]byte) {
+	c.w.simple("OK")
+	c.quit = true
+}
+
+// get replies with the value for a key, or null if the key does not exist
+func get(server *Server, c *conn, args [][]byte) {
+	c.w.bulk(server.CMap.Get(args[0]))
+}
+
+// set 
+//	Sets the value for a key, replying OK, or null if the NX or XX condition does not hold. 
+//	SET key value [NX | XX] [EX seconds | PX milliseconds]
+func set(server *Server, c *conn, args [][]byte) {
+	options := cmap.CMapPutOptions{}
+	for idx := 2; idx < len(args); idx++ {
+		switch strings.ToLower(string(args[idx])) {
+			case "nx":
+				if options.Condition == cmap.IfPresent { 
+					c.w.error("ERR syntax error") 
+					return
+				}
+
+				options.Condition = cmap.IfAbsent
+			case "xx":
+				if options.Condition == cmap.IfAbsent { 
+					c.w.error("ERR syntax error") 
+					return
+				}
+
+				options.Condition = cmap.IfPresent
+			case "ex", "px":
+				if options.TTL != 0 || idx + 1 == len(args) {
+					c.w.error("ERR syntax error")
+					return
+				}
+
+				unit := time.Second
+				if strings.ToLower(string(args[idx])) == "px" { unit = time.Millisecond }
+
+				ttl, err := strconv.ParseInt(string(args[idx + 1]), 10, 64)
+				if err != nil || ttl <= 0 || ttl > math.MaxInt64 / int64(unit) {
+					c.w.error("ERR invalid expire time in 'set' command")
+					return
+				}
+
+				options.TTL = time.Duration(ttl) * unit
+				idx++
+			default:
+				c.w.error("ERR syntax error")
+				return
+		}
+	}
+
+	written, err := server.CMap.PutWithOptions(context.Background(), args[0], args[1], options)
+	if err != nil {
+		c.w.error("ERR " + err.Error())
+		return
+	}
+
+	if written {
+		c.w.simple("OK")
+	} else { c.w.null() }
+}
+
+// del deletes keys, replying with the number of keys that existed
+func del(server *Server, c *conn, args [][]byte) {
+	deleted := int64(0)
+	for _, key := range args {
+		if _, ok := server.CMap.LoadAndDelete(key); ok { deleted++ }
+	}
+
+	c.w.integer(deleted)
+}
+
+// exists replies with the number of the given keys that exist, counting repeated keys each time
+func exists(server *Server, c *conn, args [][]byte) {
+	found := int64(0)
+	for _, key := range args {
+		if server.CMap.Get(key) != nil { found++ }
+	}
+
+	c.w.integer(found)
+}
+
+// dbsize replies with the number of keys
+func dbsize(server *Server, c *conn, args [][]byte) {
+	c.w.integer(int64(server.CMap.Len()))
+}
+
+// scan 
+//	Iterates the keys with the stateless cursor of CMap.Scan, replying with the next cursor and a page of keys. 
+//	Every key present for the whole scan is returned, and MATCH filters each page after it is read, so a page may be empty before the scan is complete. 
+//	MATCH patterns are Redis globs, see globMatch. 
+//	SCAN cursor [MATCH pattern] [COUNT count]
+func scan(server *Server, c *conn, args [][]byte) {
+	cursor, err := strconv.ParseUint(string(args[0]), 10, 64)
+	if err != nil {
+		c.w.error("ERR invalid cursor")
+		return
+	}
+
+	var pattern []byte
+	count := defaultScanCount
+	for idx := 1; idx < len(args); idx += 2 {
+		if idx + 1 == len(args) {
+			c.w.error("ERR syntax error")
+			return
+		}
+
+		switch strings.ToLower(string(args[idx])) {
+			case "match":
+				pattern = args[idx + 1]
+			case "count":
+				count, err = strconv.Atoi(string(args[idx + 1]))
+				if err != nil || count < 1 {
+					c.w.error("ERR value is not an integer or out of range")
+					return
+				}
+			default:
+				c.w.error("ERR syntax error")
+				return
+		}
+	}
+
+	page, nextCursor := server.CMap.Scan(cursor, count)
+	keys := [][]byte{}
+	for _, key := range page {
+		if pattern == nil || globMatch(pattern, key) { keys = append(keys, key) }
+	}
+
+	c.w.array(2)
+	c.w.bulk([]byte(strconv.FormatUint(nextCursor, 10)))
+	c.w.array(len(keys))
+	for _, key := range keys { c.w.bulk(key) }
+}
+
+// mget replies with the value for each key, or null for keys that do not exist
+func mget(server *Server, c *conn, args [][]byte) {
+	c.w.array(len(args))
+	for _, key := range args { c.w.bulk(server.CMap.Get(key)) }
+}
+
+// mset sets the value for each key, replying OK, or with the error of the first put that failed
+func mset(server *Server, c *conn, args [][]byte) {
+	if len(args) % 2 != 0 {
+		c.w.error("ERR wrong number of arguments for 'mset' command")
+		return
+	}
+
+	for idx := 0; idx < len(args); idx += 2 {
+		if err := server.CMap.PutContext(context.Background(), args[idx], args[idx + 1]); err != nil {
+			c.w.error("ERR " + err.Error())
+			return
+		}
+	}
+
+	c.w.simple("OK")
+}
@@ -0,0 +1,106 @@
+package resp
+
+
+//========================================= RESP Glob
+
+
+// globMatch
+//	Matches a key against a glob pattern with the semantics of Redis stringmatchlen, as used by SCAN MATCH and KEYS.
+//	* matches any run of bytes including /, ? matches a single byte, [abc], [a-z] and [^a-z] match a class of bytes, and \ escapes the next byte anywhere in the pattern.
+//	Every pattern is valid, so a malformed pattern such as an unterminated class matches the same keys it would in Redis.
+//	A * backtracks only to the most recent *, so a match takes at most the product of the pattern and key lengths.
+//
+// Parameters:
+//	pattern: the glob pattern
+//	key: the key to match
+//
+// Returns:
+//	truthy if the whole key matches the pattern
+func globMatch(pattern []byte, key []byte) bool {
+	patternIdx, keyIdx := 0, 0
+	starIdx, starKeyIdx := -1, 0
+
+	for keyIdx < len(key) {
+		if patternIdx < len(pattern) {
+			if pattern[patternIdx] == '*' {
+				starIdx, starKeyIdx = patternIdx, keyIdx
+				patternIdx++
+				continue
+			}
+
+			if matched, next := globMatchByte(pattern, patternIdx, key[keyIdx]); matched {
+				patternIdx = next
+				keyIdx++
+				continue
+			}
+		}
+
+		if starIdx == -1 { return false }
+
+		starKeyIdx++
+		patternIdx, keyIdx = starIdx + 1, starKeyIdx
+	}
+
+	for patternIdx < len(pattern) && pattern[patternIdx] == '*' { patternIdx++ }
+	return patternIdx == len(pattern)
+}
+
+// globMatchByte
+//	Matches a single byte of a key against the token of a glob pattern at an index, which is any token but *.
+//
+// Parameters:
+//	pattern: the glob pattern
+//	idx: the index of the token
+//	b: the byte of the key
+//
+// Returns:
+//	truthy if the byte matches the token, and the index of the token after it
+func globMatchByte(pattern []byte, idx int, b byte) (bool, int) {
+	switch pattern[idx] {
+		case '?':
+			return true, idx + 1
+		case '\\':
+			if idx + 1 < len(pattern) { idx++ }
+			return pattern[idx] == b, idx + 1
+		case '[':
+			return globMatchClass(pattern, idx + 1, b)
+		default:
+			return pattern[idx] == b, idx + 1
+	}
+}
+
+// globMatchClass
+//	Matches a single byte against a class of bytes, starting after the opening [.
+//	An unterminated class ends with the pattern, as in Redis.
+//
+// Parameters:
+//	pattern: the glob pattern
+//	idx: the index after the opening [
+//	b: the byte of the key
+//
+// Returns:
+//	truthy if the byte is in the class, or not in it for a class starting with ^, and the index after the closing ]
+func globMatchClass(pattern []byte, idx int, b byte) (bool, int) {
+	negate := idx < len(pattern) && pattern[idx] == '^'
+	if negate { idx++ }
+
+	matched := false
+	for ; idx < len(pattern) && pattern[idx] != ']'; idx++ {
+		switch {
+			case pattern[idx] == '\\' && idx + 1 < len(pattern):
+				idx++
+				if pattern[idx] == b { matched = true }
+			case idx + 2 < len(pattern) && pattern[idx + 1] == '-':
+				start, end := pattern[idx], pattern[idx + 2]
+				if start > end { start, end = end, start }
+				if b >= start && b <= end { matched = true }
+
+				idx += 2
+			default:
+				if pattern[idx] == b { matched = true }
+		}
+	}
+
+	if idx < len(pattern) { idx++ }
+	return matched != negate, idx
+}
@@ -0,0 +1,191 @@
+package resp
+
+import "bufio"
+import "bytes"
+import "errors"
+import "io"
+import "strconv"
+
+
+//========================================= RESP Protocol
+
+
+// maxBulkLength is the largest bulk string accepted in a request
+const maxBulkLength = 512 << 20
+
+// maxArrayLength is the largest number of arguments accepted in a request
+const maxArrayLength = 1 << 20
+
+// ErrProtocol is returned when a request or reply is not valid RESP
+var ErrProtocol = errors.New("resp: protocol error")
+
+
+// readCommand 
+//	Reads a single command from the connection, either as an array of bulk strings or as an inline command separated by spaces.
+//	Inline arguments are copied out of the buffer of the reader, since commands like SET keep them after the next command is read.
+//
+// Parameters:
+//	reader: the buffered reader for the connection
+//
+// Returns:
+//	The command name followed by its arguments, and the error reading the command
+func readCommand(reader *bufio.Reader) ([][]byte, error) {
+	prefix, err := reader.Peek(1)
+	if err != nil { return nil, err }
+
+	if prefix[0] != '*' {
+		line, lineErr := readLine(reader)
+		if lineErr != nil { return nil, lineErr }
+
+		fields := bytes.Fields(line)
+		for idx, field := range fields { fields[idx] = bytes.Clone(field) }
+
+		return fields, nil
+	}
+
+	reader.Discard(1)
+	count, err := readInt(reader)
+	if err != nil { return nil, err }
+	if count < 0 || count > maxArrayLength { return nil, ErrProtocol }
+
+	args := make([][]byte, count)
+	for idx := range args {
+		if prefix, err := reader.ReadByte(); err != nil || prefix != '$' { 
+			if err != nil { return nil, err }
+			return nil, ErrProtocol
+		}
+
+		arg, argErr := readBulk(reader)
+		if argErr != nil { return nil, argErr }
+		if arg == nil { return nil, ErrProtocol }
+
+		args[idx] = arg
+	}
+
+	return args, nil
+}
+
+// readLine 
+//	Reads a line terminated by \r\n, without the terminator.
+//
+// Parameters:
+//	reader: the buffered reader for the connection
+//
+// Returns:
+//	The line, and the error reading it
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull { return nil, ErrProtocol }
+	if err != nil { return nil, err }
+	if len(line) < 2 || line[len(line) - 2] != '\r' { return nil, ErrProtocol }
+
+	return line[:len(line) - 2], nil
+}
+
+// readInt 
+//	Reads a line holding a decimal integer.
+//
+// Parameters:
+//	reader: the buffered reader for the connection
+//
+// Returns:
+//	The integer, and the error reading it
+func readInt(reader *bufio.Reader) (int64, error) {
+	line, err := readLine(reader)
+	if err != nil { return 0, err }
+
+	value, parseErr := strconv.ParseInt(string(line), 10, 64)
+	if parseErr != nil { return 0, ErrProtocol }
+
+	return value, nil
+}
+
+// readBulk 
+//	Reads the length and contents of a bulk string, after its $ prefix.
+//
+// Parameters:
+//	reader: the buffered reader for the connection
+//
+// Returns:
+//	The contents of the bulk string or nil for a null bulk string, and the error reading it
+func readBulk(reader *bufio.Reader) ([]byte, error) {
+	length, err := readInt(reader)
+	if err != nil { return nil, err }
+	if length == -1 { return nil, nil }
+	if length < 0 || length > maxBulkLength { return nil, ErrProtocol }
+
+	data := make([]byte, length + 2)
+	if _, err := io.ReadFull(reader, data); err != nil { return nil, err }
+	if data[length] != '\r' || data[length + 1] != '\n' { return nil, ErrProtocol }
+
+	return data[:length], nil
+}
+
+// writer 
+//	Writes replies in the protocol version negotiated by the connection.
+//
+// Properties
+//	Writer: the buffered writer for the connection
+//	proto: the protocol version, 2 or 3
+type writer struct {
+	*bufio.Writer
+	proto int
+}
+
+// simple writes a simple string reply
+func (w *writer) simple(value string) {
+	w.WriteByte('+')
+	w.WriteString(value)
+	w.WriteString("\r\n")
+}
+
+// error writes an error reply, where the message starts with the error code
+func (w *writer) error(message string) {
+	w.WriteByte('-')
+	w.WriteString(message)
+	w.WriteString("\r\n")
+}
+
+// integer writes an integer reply
+func (w *writer) integer(value int64) {
+	w.WriteByte(':')
+	w.WriteString(strconv.FormatInt(value, 10))
+	w.WriteString("\r\n")
+}
+
+// bulk writes a bulk string reply, or a null reply if the value is nil
+func (w *writer) bulk(value []byte) {
+	if value == nil {
+		w.null()
+		return
+	}
+
+	w.WriteByte('$')
+	w.WriteString(strconv.Itoa(len(value)))
+	w.WriteString("\r\n")
+	w.Write(value)
+	w.WriteString("\r\n")
+}
+
+// null writes a null reply, which is a null bulk string in RESP2
+func (w *writer) null() {
+	if w.proto == 3 {
+		w.WriteString("_\r\n")
+	} else { w.WriteString("$-1\r\n") }
+}
+
+// array writes the header of an array reply with the given number of elements
+func (w *writer) array(count int) {
+	w.WriteByte('*')
+	w.WriteString(strconv.Itoa(count))
+	w.WriteString("\r\n")
+}
+
+// mapHeader writes the header of a map reply with the given number of pairs, which is a flat array of keys and values in RESP2
+func (w *writer) mapHeader(count int) {
+	if w.proto == 3 {
+		w.WriteByte('%')
+		w.WriteString(strconv.Itoa(count))
+		w.WriteString("\r\n")
+	} else { w.array(2 * count) }
+}
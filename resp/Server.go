@@ -0,0 +1,211 @@
+package resp
+
+import "bufio"
+import "errors"
+import "io"
+import "net"
+import "strings"
+import "sync"
+
+import "github.com/sirgallo/cmap"
+
+
+//========================================= RESP Server
+
+
+// ErrServerClosed is returned by Serve once the server is closed
+var ErrServerClosed = errors.New("resp: server closed")
+
+
+// Server 
+//	Serves a CMap over the RESP2 and RESP3 protocols, so it can be used with Redis clients.
+//
+// Properties
+//	CMap: the map being served
+//	mu: guards the open listeners and connections
+//	open: the listeners being served and the open client connections
+//	closed: whether the server has been closed
+//	wg: tracks the connection goroutines
+type Server struct {
+	CMap *cmap.CMap[uint64]
+	mu sync.Mutex
+	open map[io.Closer]struct{}
+	closed bool
+	wg sync.WaitGroup
+}
+
+// conn 
+//	The state of a single client connection.
+//
+// Properties
+//	reader: the buffered reader for the connection
+//	w: the reply writer for the connection
+//	quit: whether the client asked to close the connection
+type conn struct {
+	reader *bufio.Reader
+	w *writer
+	quit bool
+}
+
+
+// NewServer 
+//	Creates a server for a map.
+//
+// Parameters:
+//	cMap: the map to serve
+//
+// Returns:
+//	The new server
+func NewServer(cMap *cmap.CMap[uint64]) *Server {
+	return &Server{
+		CMap: cMap,
+		open: make(map[io.Closer]struct{}),
+	}
+}
+
+// ListenAndServe 
+//	Listens on a TCP address and serves connections until the server is closed.
+//
+// Parameters:
+//	addr: the address to listen on
+//
+// Returns:
+//	ErrServerClosed once the server is closed, or the error listening or accepting connections
+func (server *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil { return err }
+
+	return server.Serve(listener)
+}
+
+// Serve 
+//	Accepts connections on a listener, serving each on its own goroutine, until the server is closed.
+//
+// Parameters:
+//	listener: the listener to accept connections on. It is closed when Serve returns
+//
+// Returns:
+//	ErrServerClosed once the server is closed, or the error accepting connections
+func (server *Server) Serve(listener net.Listener) error {
+	if ! server.track(listener) {
+		listener.Close()
+		return ErrServerClosed
+	}
+
+	defer server.untrack(listener)
+
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if server.isClosed() { return ErrServerClosed }
+			return err
+		}
+
+		if ! server.track(netConn) {
+			netConn.Close()
+			return ErrServerClosed
+		}
+
+		server.wg.Add(1)
+		go server.serveConn(netConn)
+	}
+}
+
+// Close 
+//	Closes every listener and client connection, and waits for the connection goroutines to return.
+//
+// Returns:
+//	nil, the error is kept for compatibility with io.Closer
+func (server *Server) Close() error {
+	server.mu.Lock()
+	server.closed = true
+	for closer := range server.open { closer.Close() }
+	server.mu.Unlock()
+
+	server.wg.Wait()
+	return nil
+}
+
+// serveConn 
+//	Reads commands from a connection and writes their replies until the connection is closed. 
+//	Replies are buffered and flushed once there are no more pipelined commands to read.
+//
+// Parameters:
+//	netConn: the client connection
+func (server *Server) serveConn(netConn net.Conn) {
+	defer server.wg.Done()
+	defer server.untrack(netConn)
+
+	c := &conn{
+		reader: bufio.NewReader(netConn),
+		w: &writer{ Writer: bufio.NewWriter(netConn), proto: 2 },
+	}
+
+	for ! c.quit {
+		args, err := readCommand(c.reader)
+		if err != nil {
+			if err == ErrProtocol { 
+				c.w.error("ERR Protocol error")
+				c.w.Flush()
+			}
+
+			return
+		}
+
+		if len(args) > 0 { server.dispatch(c, args) }
+		if c.reader.Buffered() == 0 || c.quit { 
+			if c.w.Flush() != nil { return }
+		}
+	}
+}
+
+// dispatch 
+//	Runs a command and writes its reply.
+//
+// Parameters:
+//	c: the client connection
+//	args: the command name followed by its arguments
+func (server *Server) dispatch(c *conn, args [][]byte) {
+	name := strings.ToLower(string(args[0]))
+	command, ok := commands[name]
+	if ! ok {
+		c.w.error("ERR unknown command '" + string(args[0]) + "'")
+		return
+	}
+
+	if len(args) < command.minArgs || (command.maxArgs >= 0 && len(args) > command.maxArgs) {
+		c.w.error("ERR wrong number of arguments for '" + name + "' command")
+		return
+	}
+
+	command.run(server, c, args[1:])
+}
+
+// track adds a listener or connection to the open set, returning falsey if the server is closed
+func (server *Server) track(closer io.Closer) bool {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.closed { return false }
+
+	server.open[closer] = struct{}{}
+	return true
+}
+
+// untrack closes a listener or connection and removes it from the open set
+func (server *Server) untrack(closer io.Closer) {
+	closer.Close()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	delete(server.open, closer)
+}
+
+// isClosed returns whether the server has been closed
+func (server *Server) isClosed() bool {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return server.closed
+}
@@ -0,0 +1,133 @@
+package cmaptests
+
+import "bytes"
+import "context"
+import "errors"
+import "sync"
+import "sync/atomic"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapBackoff(t *testing.T) {
+	inputSize := 10000
+	keyVals := make([]KeyVal, inputSize)
+
+	for idx := range keyVals {
+		randomBytes, _ := GenerateRandomBytes(32)
+		keyVals[idx] = KeyVal{ Key: randomBytes, Value: randomBytes }
+	}
+
+	strategies := map[string]cmap.Backoff{
+		"none": cmap.NoBackoff{},
+		"spin then yield": cmap.SpinYieldBackoff{ Spins: 4, SpinIterations: 16 },
+		"exponential": cmap.ExponentialBackoff{ Base: time.Microsecond, Max: time.Millisecond },
+	}
+
+	for name, strategy := range strategies {
+		t.Run("test concurrent puts with " + name + " backoff", func(t *testing.T) {
+			cMap := cmap.NewCMap[uint32]()
+			cMap.Backoff = strategy
+
+			var insertWG sync.WaitGroup
+
+			for _, val := range keyVals {
+				insertWG.Add(1)
+				go func(val KeyVal) {
+					defer insertWG.Done()
+
+					if ! cMap.Put(val.Key, val.Value) { t.Error("put failed without max retries set") }
+				}(val)
+			}
+
+			insertWG.Wait()
+
+			for _, val := range keyVals {
+				value := cMap.Get(val.Key)
+				if ! bytes.Equal(value, val.Value) {
+					t.Errorf("actual value not equal to expected: actual(%s), expected(%s)", value, val.Value)
+				}
+			}
+		})
+	}
+
+	t.Run("test max retries", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		metrics := cmap.NewCMapMetrics()
+		cMap.Metrics = metrics
+		cMap.MaxRetries = 1
+
+		var failed atomic.Uint64
+		var insertWG sync.WaitGroup
+
+		for _, val := range keyVals {
+			insertWG.Add(1)
+			go func(val KeyVal) {
+				defer insertWG.Done()
+
+				err := cMap.PutContext(context.Background(), val.Key, val.Value)
+				if errors.Is(err, cmap.ErrMaxRetriesExceeded) {
+					failed.Add(1)
+				} else if err != nil { t.Errorf("unexpected error on put: %s", err) }
+			}(val)
+		}
+
+		insertWG.Wait()
+
+		putMetrics := &metrics.Ops[cmap.PutOp]
+		maxRetryFailures := putMetrics.CASFailures.Load() - putMetrics.Retries.Load()
+		t.Logf("cas failures: %d, retries: %d, failed puts: %d", putMetrics.CASFailures.Load(), putMetrics.Retries.Load(), failed.Load())
+
+		if maxRetryFailures != failed.Load() {
+			t.Errorf("failed puts should be the cas failures that were not retried: actual(%d), expected(%d)", failed.Load(), maxRetryFailures)
+		}
+	})
+
+	t.Run("test cancelled context", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := cMap.PutContext(ctx, []byte("hello"), []byte("world"))
+		if ! errors.Is(err, context.Canceled) {
+			t.Errorf("put with cancelled context should return context.Canceled: actual(%v)", err)
+		}
+
+		if cMap.Get([]byte("hello")) != nil { t.Error("put with cancelled context should not insert the key") }
+
+		err = cMap.DeleteContext(ctx, []byte("hello"))
+		if ! errors.Is(err, context.Canceled) {
+			t.Errorf("delete with cancelled context should return context.Canceled: actual(%v)", err)
+		}
+	})
+
+	t.Run("test exponential backoff wait is cancelled", func(t *testing.T) {
+		backoff := cmap.ExponentialBackoff{ Base: time.Second, Max: time.Minute }
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := backoff.Wait(ctx, 10)
+		if ! errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+			t.Errorf("wait should return on context deadline: err(%v), elapsed(%s)", err, time.Since(start))
+		}
+	})
+
+	t.Run("test delete of missing keys completes", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.MaxRetries = 10
+
+		for _, val := range keyVals { cMap.Put(val.Key, val.Value) }
+
+		for range make([]int, inputSize) {
+			missing, _ := GenerateRandomBytes(32)
+			if ! cMap.Delete(missing) { t.Error("delete of a missing key should complete without retrying") }
+		}
+	})
+
+	t.Log("Done")
+}
@@ -0,0 +1,126 @@
+package cmaptests
+
+import "fmt"
+import "sync"
+import "sync/atomic"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapCache(t *testing.T) {
+	t.Run("test entry capacity", func(t *testing.T) {
+		cache := cmap.NewCMapCache[uint32](100, 0)
+		for i := 0; i < 1000; i++ {
+			cache.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value"))
+		}
+
+		stats := cache.Stats()
+		if stats.Entries != 100 { t.Errorf("actual entries not equal to expected: actual(%d), expected(%d)", stats.Entries, 100) }
+		if stats.Evictions != 900 { t.Errorf("actual evictions not equal to expected: actual(%d), expected(%d)", stats.Evictions, 900) }
+		if cache.CMap.Stats().LeafCount != 100 { t.Error("leaf count should match the entry count on the root") }
+	})
+
+	t.Run("test byte capacity", func(t *testing.T) {
+		cache := cmap.NewCMapCache[uint64](0, 1000)
+		for i := 0; i < 1000; i++ {
+			cache.Put([]byte(fmt.Sprintf("key%04d", i)), []byte("value"))
+		}
+
+		if cache.Stats().Bytes > 1000 { t.Errorf("bytes exceed capacity: %d", cache.Stats().Bytes) }
+		if cache.Len() != 83 { t.Errorf("actual entries not equal to expected: actual(%d), expected(%d)", cache.Len(), 83) }
+	})
+
+	t.Run("test recently used keys are kept", func(t *testing.T) {
+		cache := cmap.NewCMapCache[uint32](200, 0)
+		cache.SampleSize = 10
+
+		hot := []byte("hot")
+		cache.Put(hot, []byte("value"))
+
+		for i := 0; i < 5000; i++ {
+			cache.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value"))
+			if cache.Get(hot) == nil { 
+				t.Fatalf("hot key evicted after %d puts", i) 
+			}
+		}
+	})
+
+	t.Run("test eviction callback", func(t *testing.T) {
+		cache := cmap.NewCMapCache[uint32](10, 0)
+
+		evicted := map[string]string{}
+		cache.OnEvict = func(key []byte, value []byte) { evicted[string(key)] = string(value) }
+
+		for i := 0; i < 20; i++ {
+			cache.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+		}
+
+		if len(evicted) != 10 { t.Errorf("actual evicted not equal to expected: actual(%d), expected(%d)", len(evicted), 10) }
+		for key, value := range evicted {
+			if "value" + key[3:] != value { t.Errorf("evicted value does not match key: %s, %s", key, value) }
+			if cache.Get([]byte(key)) != nil { t.Errorf("evicted key still present: %s", key) }
+		}
+	})
+
+	t.Run("test hits and misses", func(t *testing.T) {
+		cache := cmap.NewCMapCache[uint32](10, 0)
+		cache.Put([]byte("hello"), []byte("world"))
+
+		cache.Get([]byte("hello"))
+		cache.Get([]byte("hello"))
+		cache.Get([]byte("missing"))
+
+		stats := cache.Stats()
+		if stats.Hits != 2 || stats.Misses != 1 { t.Errorf("unexpected hits and misses: hits(%d), misses(%d)", stats.Hits, stats.Misses) }
+	})
+
+	t.Run("test concurrent puts stay within capacity", func(t *testing.T) {
+		cache := cmap.NewCMapCache[uint32](500, 0)
+
+		var evicted atomic.Uint64
+		cache.OnEvict = func(key []byte, value []byte) { evicted.Add(1) }
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 2000; i++ {
+					cache.Put([]byte(fmt.Sprintf("key%d-%d", w, i)), []byte("value"))
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		if cache.Len() != 500 { t.Errorf("actual entries not equal to expected: actual(%d), expected(%d)", cache.Len(), 500) }
+		if evicted.Load() != 16000 - 500 { t.Errorf("actual evicted not equal to expected: actual(%d), expected(%d)", evicted.Load(), 16000 - 500) }
+	})
+
+	t.Run("test concurrent evictions do not evict below capacity", func(t *testing.T) {
+		cache := cmap.NewCMapCache[uint32](10, 0)
+
+		var belowCapacity atomic.Uint64
+		cache.OnEvict = func(key []byte, value []byte) {
+			if cache.Len() < 10 { belowCapacity.Add(1) }
+		}
+
+		var wg sync.WaitGroup
+		for w := 0; w < 16; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 2000; i++ {
+					cache.Put([]byte(fmt.Sprintf("key%d-%d", w, i)), []byte("value"))
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		if belowCapacity.Load() != 0 { t.Errorf("evictions left the cache below capacity %d times", belowCapacity.Load()) }
+		if cache.Len() != 10 { t.Errorf("actual entries not equal to expected: actual(%d), expected(%d)", cache.Len(), 10) }
+		if cache.Stats().Evictions != 32000 - 10 { t.Errorf("actual evictions not equal to expected: actual(%d), expected(%d)", cache.Stats().Evictions, 32000 - 10) }
+	})
+}
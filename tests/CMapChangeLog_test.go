@@ -0,0 +1,144 @@
+package cmaptests
+
+import "fmt"
+import "path/filepath"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapChangeLog(t *testing.T) {
+	t.Run("test changes since", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.Put([]byte("before"), []byte("log"))
+
+		if _, err := cMap.ChangesSince(0); err != cmap.ErrChangeLogNotEnabled { t.Errorf("expected ErrChangeLogNotEnabled, got %v", err) }
+		if err := cMap.EnableChangeLog(cmap.CMapChangeLogOptions{}); err != nil { t.Fatal(err) }
+		if err := cMap.EnableChangeLog(cmap.CMapChangeLogOptions{}); err != cmap.ErrChangeLogEnabled { t.Errorf("expected ErrChangeLogEnabled, got %v", err) }
+
+		start := cMap.Seq()
+		cMap.Put([]byte("hello"), []byte("world"))
+		cMap.Put([]byte("hello"), []byte("there"))
+		cMap.Delete([]byte("hello"))
+
+		changes, err := cMap.ChangesSince(start)
+		if err != nil { t.Fatal(err) }
+		if len(changes) != 3 { t.Fatalf("actual changes not equal to expected: actual(%d), expected(%d)", len(changes), 3) }
+
+		for idx, change := range changes {
+			if change.Seq != start + uint64(idx) + 1 { t.Errorf("sequence should be contiguous: actual(%d), expected(%d)", change.Seq, start + uint64(idx) + 1) }
+		}
+
+		if changes[2].Type != cmap.DeleteEvent || string(changes[2].OldValue) != "there" { t.Errorf("unexpected change: %+v", changes[2]) }
+
+		tail, err := cMap.ChangesSince(changes[1].Seq)
+		if err != nil || len(tail) != 1 || tail[0].Seq != changes[2].Seq { t.Errorf("unexpected tail: %+v, %v", tail, err) }
+
+		if _, err := cMap.ChangesSince(start - 1); err != cmap.ErrChangesTruncated { t.Errorf("expected ErrChangesTruncated, got %v", err) }
+		if err := cMap.DisableChangeLog(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test ring truncation", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.EnableChangeLog(cmap.CMapChangeLogOptions{ Capacity: 10 })
+		start := cMap.Seq()
+
+		for i := 0; i < 25; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		if _, err := cMap.ChangesSince(start); err != cmap.ErrChangesTruncated { t.Errorf("expected ErrChangesTruncated, got %v", err) }
+
+		changes, err := cMap.ChangesSince(start + 15)
+		if err != nil || len(changes) != 10 { t.Errorf("ring should hold the last 10 changes: %d, %v", len(changes), err) }
+		if _, err := cMap.ChangesSince(start + 14); err != cmap.ErrChangesTruncated { t.Errorf("expected ErrChangesTruncated, got %v", err) }
+	})
+
+	t.Run("test sequences without changes", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		if err := cMap.EnableChangeLog(cmap.CMapChangeLogOptions{ Capacity: 2 }); err != nil { t.Fatal(err) }
+		start := cMap.Seq()
+
+		cMap.Put([]byte("a"), []byte("1"))
+		cMap.EnableOrderedIndex()
+		cMap.Put([]byte("b"), []byte("2"))
+
+		changes, err := cMap.ChangesSince(start)
+		if err != nil || len(changes) != 2 { t.Fatalf("expected both changes around the index, got %+v, %v", changes, err) }
+		if string(changes[0].Key) != "a" || string(changes[1].Key) != "b" || changes[1].Seq != start + 3 { t.Errorf("unexpected changes: %+v", changes) }
+
+		cMap.Put([]byte("c"), []byte("3"))
+
+		if _, err := cMap.ChangesSince(start); err != cmap.ErrChangesTruncated { t.Errorf("expected ErrChangesTruncated once a is dropped, got %v", err) }
+
+		changes, err = cMap.ChangesSince(start + 1)
+		if err != nil || len(changes) != 2 || string(changes[1].Key) != "c" { t.Errorf("expected the changes after a, got %+v, %v", changes, err) }
+	})
+
+	t.Run("test replay from file", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		err := cMap.EnableChangeLog(cmap.CMapChangeLogOptions{ Capacity: 4, Path: filepath.Join(t.TempDir(), "changes.log") })
+		if err != nil { t.Fatal(err) }
+
+		start := cMap.Seq()
+		for i := 0; i < 50; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i % 20)), []byte(fmt.Sprintf("value%d", i))) }
+		cMap.Delete([]byte("key0"))
+		cMap.Put([]byte("empty"), []byte{})
+
+		changes, err := cMap.ChangesSince(start)
+		if err != nil { t.Fatal(err) }
+		if len(changes) != 52 { t.Fatalf("actual changes not equal to expected: actual(%d), expected(%d)", len(changes), 52) }
+
+		replica := map[string][]byte{}
+		for _, change := range changes {
+			if change.Type == cmap.DeleteEvent {
+				delete(replica, string(change.Key))
+			} else { replica[string(change.Key)] = change.NewValue }
+		}
+
+		if len(replica) != cMap.Len() { t.Errorf("replica len not equal to map: replica(%d), map(%d)", len(replica), cMap.Len()) }
+		for key, value := range replica {
+			if string(cMap.Get([]byte(key))) != string(value) { t.Errorf("replica value does not match for key %s", key) }
+		}
+
+		if replica["empty"] == nil { t.Error("empty value should be distinguished from a missing value") }
+		if err := cMap.DisableChangeLog(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test concurrent writers replay", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.EnableChangeLog(cmap.CMapChangeLogOptions{ Capacity: 8 * 500 })
+		start := cMap.Seq()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					key := []byte(fmt.Sprintf("key%d", i % 50))
+					if i % 7 == 0 {
+						cMap.Delete(key)
+					} else { cMap.Put(key, []byte(fmt.Sprintf("%d-%d", w, i))) }
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		changes, err := cMap.ChangesSince(start)
+		if err != nil { t.Fatal(err) }
+
+		replica := map[string]string{}
+		for idx, change := range changes {
+			if change.Seq != start + uint64(idx) + 1 { t.Fatalf("sequence should be contiguous at %d", idx) }
+			if change.Type == cmap.DeleteEvent {
+				delete(replica, string(change.Key))
+			} else { replica[string(change.Key)] = string(change.NewValue) }
+		}
+
+		if len(replica) != cMap.Len() { t.Errorf("replica len not equal to map: replica(%d), map(%d)", len(replica), cMap.Len()) }
+		for key, value := range replica {
+			if string(cMap.Get([]byte(key))) != value { t.Errorf("replica value does not match for key %s", key) }
+		}
+	})
+}
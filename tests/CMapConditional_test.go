@@ -0,0 +1,73 @@
+package cmaptests
+
+import "context"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapConditional(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+	clock := newTestClock()
+	cMap.Clock = clock
+	ctx := context.Background()
+
+	t.Run("test put if absent and if present", func(t *testing.T) {
+		written, _ := cMap.PutWithOptions(ctx, []byte("key"), []byte("first"), cmap.CMapPutOptions{ Condition: cmap.IfPresent })
+		if written { t.Error("put if present should not write a missing key") }
+
+		written, _ = cMap.PutWithOptions(ctx, []byte("key"), []byte("first"), cmap.CMapPutOptions{ Condition: cmap.IfAbsent })
+		if ! written { t.Error("put if absent should write a missing key") }
+
+		written, _ = cMap.PutWithOptions(ctx, []byte("key"), []byte("second"), cmap.CMapPutOptions{ Condition: cmap.IfAbsent })
+		if written || string(cMap.Get([]byte("key"))) != "first" { t.Error("put if absent should not overwrite an existing key") }
+
+		written, _ = cMap.PutWithOptions(ctx, []byte("key"), []byte("second"), cmap.CMapPutOptions{ Condition: cmap.IfPresent })
+		if ! written || string(cMap.Get([]byte("key"))) != "second" { t.Error("put if present should overwrite an existing key") }
+	})
+
+	t.Run("test expired keys are absent", func(t *testing.T) {
+		cMap.PutWithOptions(ctx, []byte("session"), []byte("token"), cmap.CMapPutOptions{ TTL: time.Second })
+		clock.Advance(time.Second)
+
+		written, _ := cMap.PutWithOptions(ctx, []byte("session"), []byte("new"), cmap.CMapPutOptions{ Condition: cmap.IfAbsent })
+		if ! written || string(cMap.Get([]byte("session"))) != "new" { t.Error("put if absent should replace an expired key") }
+	})
+
+	t.Run("test put matching the current value", func(t *testing.T) {
+		isFirst := func(value []byte) bool { return string(value) == "first" }
+
+		written, _ := cMap.PutWithOptions(ctx, []byte("matched"), []byte("second"), cmap.CMapPutOptions{ Match: isFirst })
+		if written { t.Error("put with match should not write a missing key") }
+
+		cMap.Put([]byte("matched"), []byte("first"))
+		written, _ = cMap.PutWithOptions(ctx, []byte("matched"), []byte("second"), cmap.CMapPutOptions{ Match: isFirst, TTL: time.Second })
+		if ! written || string(cMap.Get([]byte("matched"))) != "second" { t.Error("put with match should write when the value matches") }
+
+		written, _ = cMap.PutWithOptions(ctx, []byte("matched"), []byte("third"), cmap.CMapPutOptions{ Match: isFirst })
+		if written { t.Error("put with match should not write when the value does not match") }
+
+		clock.Advance(time.Second)
+		if cMap.Get([]byte("matched")) != nil { t.Error("put with match should keep its ttl") }
+	})
+
+	t.Run("test load and delete", func(t *testing.T) {
+		value, loaded := cMap.LoadAndDelete([]byte("key"))
+		if ! loaded || string(value) != "second" { t.Errorf("unexpected load and delete: %s, %t", value, loaded) }
+
+		if _, loaded := cMap.LoadAndDelete([]byte("key")); loaded { t.Error("deleted key should not be loaded") }
+	})
+}
+
+func TestCMapCompareAndSwap(t *testing.T) {
+	cMap := cmap.NewCMap[uint64]()
+	cMap.Put([]byte("key"), []byte("first"))
+
+	if cMap.CompareAndSwap([]byte("key"), []byte("wrong"), []byte("second")) { t.Error("swap should fail when the old value does not match") }
+	if ! cMap.CompareAndSwap([]byte("key"), []byte("first"), []byte("second")) { t.Error("swap should succeed when the old value matches") }
+	if cMap.CompareAndSwap([]byte("missing"), nil, []byte("value")) { t.Error("swap should fail for a missing key") }
+
+	if cMap.CompareAndDelete([]byte("key"), []byte("first")) { t.Error("delete should fail when the old value does not match") }
+	if ! cMap.CompareAndDelete([]byte("key"), []byte("second")) || cMap.Get([]byte("key")) != nil { t.Error("delete should succeed when the old value matches") }
+}
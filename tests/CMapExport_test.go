@@ -0,0 +1,89 @@
+package cmaptests
+
+import "bytes"
+import "encoding/json"
+import "fmt"
+import "strings"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+type exportNode struct {
+	Type string
+	Level int
+	Bitmap string
+	Keys []string
+	Leaves int
+	Children []struct {
+		Index int
+		Node *exportNode
+	}
+}
+
+func countExportedLeaves(node *exportNode) int {
+	switch node.Type {
+		case "leaf":
+			return 1
+		case "collision":
+			return len(node.Keys)
+		case "truncated":
+			return node.Leaves
+	}
+
+	total := 0
+	for _, child := range node.Children { total += countExportedLeaves(child.Node) }
+	return total
+}
+
+func TestCMapExport(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+	for i := 0; i < 500; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+	cMap.Put([]byte("a very long key that should be abbreviated"), []byte("value"))
+	cMap.Put([]byte{ 0, 1, 2 }, []byte("binary"))
+
+	t.Run("test export json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cMap.ExportJSON(&buf, 0); err != nil { t.Fatal(err) }
+
+		var root exportNode
+		if err := json.Unmarshal(buf.Bytes(), &root); err != nil { t.Fatal(err) }
+
+		if root.Type != "internal" || len(root.Bitmap) != 32 { t.Errorf("unexpected root: %s, %s", root.Type, root.Bitmap) }
+		if countExportedLeaves(&root) != cMap.Len() { t.Errorf("actual leaves not equal to expected: actual(%d), expected(%d)", countExportedLeaves(&root), cMap.Len()) }
+
+		for _, child := range root.Children {
+			if root.Bitmap[31 - child.Index] != '1' { t.Errorf("edge index %d should be set in bitmap %s", child.Index, root.Bitmap) }
+		}
+
+		if ! strings.Contains(buf.String(), `"a very long key ..."`) || ! strings.Contains(buf.String(), `"0x000102"`) { t.Error("keys should be abbreviated") }
+	})
+
+	t.Run("test depth limit", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cMap.ExportJSON(&buf, 1); err != nil { t.Fatal(err) }
+
+		var root exportNode
+		json.Unmarshal(buf.Bytes(), &root)
+
+		truncated := 0
+		for _, child := range root.Children {
+			if child.Node.Type == "truncated" { truncated++ }
+			if len(child.Node.Children) != 0 { t.Error("children below the depth limit should not be rendered") }
+		}
+
+		if truncated == 0 || countExportedLeaves(&root) != cMap.Len() { t.Errorf("truncated subtrees should keep their leaf counts: truncated(%d), leaves(%d)", truncated, countExportedLeaves(&root)) }
+	})
+
+	t.Run("test export dot", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := cMap.ExportDOT(&buf, 0); err != nil { t.Fatal(err) }
+
+		dot := buf.String()
+		if ! strings.HasPrefix(dot, "digraph cmap {") || ! strings.HasSuffix(dot, "}\n") { t.Error("dot output should be a digraph") }
+
+		nodes, edges := strings.Count(dot, "[shape="), strings.Count(dot, " -> ")
+		if edges != nodes - 1 { t.Errorf("the trie should render as a tree: nodes(%d), edges(%d)", nodes, edges) }
+		if strings.Count(dot, "shape=box") != cMap.Len() { t.Errorf("every leaf should be rendered: leaves(%d)", strings.Count(dot, "shape=box")) }
+	})
+}
@@ -0,0 +1,184 @@
+package cmaptests
+
+import "bufio"
+import "encoding/base64"
+import "encoding/json"
+import "errors"
+import "fmt"
+import "io"
+import "net/http"
+import "net/http/httptest"
+import "strings"
+import "testing"
+import "testing/iotest"
+import "time"
+
+import "github.com/sirgallo/cmap"
+import "github.com/sirgallo/cmap/cmaphttp"
+
+
+func doRequest(t *testing.T, server *httptest.Server, method string, path string, body string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL + path, strings.NewReader(body))
+	if err != nil { t.Fatal(err) }
+	for name, value := range headers { req.Header.Set(name, value) }
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil { t.Fatal(err) }
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp, string(respBody)
+}
+
+func TestCMapHTTP(t *testing.T) {
+	cMap := cmap.NewCMap[uint64]()
+	server := httptest.NewServer(cmaphttp.NewHandler(cMap))
+	defer server.Close()
+
+	t.Run("test put get delete", func(t *testing.T) {
+		resp, _ := doRequest(t, server, http.MethodPut, "/keys/hello", "world", nil)
+		if resp.StatusCode != http.StatusNoContent || resp.Header.Get("ETag") != cmaphttp.Digest([]byte("world")) { t.Errorf("unexpected put response: %d, %s", resp.StatusCode, resp.Header.Get("ETag")) }
+
+		resp, body := doRequest(t, server, http.MethodGet, "/keys/hello", "", nil)
+		if resp.StatusCode != http.StatusOK || body != "world" { t.Errorf("unexpected get response: %d, %s", resp.StatusCode, body) }
+
+		resp, _ = doRequest(t, server, http.MethodGet, "/keys/hello", "", map[string]string{ "If-None-Match": cmaphttp.Digest([]byte("world")) })
+		if resp.StatusCode != http.StatusNotModified { t.Errorf("expected not modified, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodDelete, "/keys/hello", "", nil)
+		if resp.StatusCode != http.StatusNoContent { t.Errorf("expected no content, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodGet, "/keys/hello", "", nil)
+		if resp.StatusCode != http.StatusNotFound { t.Errorf("expected not found, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodDelete, "/keys/hello", "", nil)
+		if resp.StatusCode != http.StatusNotFound { t.Errorf("expected not found, got %d", resp.StatusCode) }
+	})
+
+	t.Run("test base64 encoding", func(t *testing.T) {
+		key, value := []byte{ 0, 1, 2, '/' }, []byte{ 255, 254, 0 }
+		path := "/keys/" + base64.URLEncoding.EncodeToString(key) + "?encoding=base64"
+
+		resp, _ := doRequest(t, server, http.MethodPut, path, base64.URLEncoding.EncodeToString(value), nil)
+		if resp.StatusCode != http.StatusNoContent { t.Fatalf("unexpected put response: %d", resp.StatusCode) }
+		if string(cMap.Get(key)) != string(value) { t.Error("binary value should be decoded") }
+
+		_, body := doRequest(t, server, http.MethodGet, path, "", nil)
+		if body != base64.URLEncoding.EncodeToString(value) { t.Errorf("unexpected get response: %s", body) }
+
+		resp, _ = doRequest(t, server, http.MethodGet, "/keys/hello?encoding=hex", "", nil)
+		if resp.StatusCode != http.StatusBadRequest { t.Errorf("expected bad request, got %d", resp.StatusCode) }
+	})
+
+	t.Run("test conditional requests", func(t *testing.T) {
+		resp, _ := doRequest(t, server, http.MethodPut, "/keys/cond", "first", map[string]string{ "If-Match": "*" })
+		if resp.StatusCode != http.StatusPreconditionFailed { t.Errorf("if match * should fail for a missing key, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodPut, "/keys/cond", "first", map[string]string{ "If-None-Match": "*" })
+		if resp.StatusCode != http.StatusNoContent { t.Errorf("if none match * should create a missing key, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodPut, "/keys/cond", "second", map[string]string{ "If-None-Match": "*" })
+		if resp.StatusCode != http.StatusPreconditionFailed { t.Errorf("if none match * should fail for an existing key, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodPut, "/keys/cond", "second", map[string]string{ "If-Match": cmaphttp.Digest([]byte("stale")) })
+		if resp.StatusCode != http.StatusPreconditionFailed { t.Errorf("stale if match should fail, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodPut, "/keys/cond", "second", map[string]string{ "If-Match": cmaphttp.Digest([]byte("first")) })
+		if resp.StatusCode != http.StatusNoContent || string(cMap.Get([]byte("cond"))) != "second" { t.Errorf("matching if match should swap, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodDelete, "/keys/cond", "", map[string]string{ "If-Match": cmaphttp.Digest([]byte("first")) })
+		if resp.StatusCode != http.StatusPreconditionFailed { t.Errorf("stale if match should fail, got %d", resp.StatusCode) }
+
+		resp, _ = doRequest(t, server, http.MethodDelete, "/keys/cond", "", map[string]string{ "If-Match": cmaphttp.Digest([]byte("second")) })
+		if resp.StatusCode != http.StatusNoContent || cMap.Get([]byte("cond")) != nil { t.Errorf("matching if match should delete, got %d", resp.StatusCode) }
+	})
+
+	t.Run("test conditional put keeps ttl", func(t *testing.T) {
+		clock := newTestClock()
+		ttlMap := cmap.NewCMap[uint64]()
+		ttlMap.Clock = clock
+		ttlServer := httptest.NewServer(cmaphttp.NewHandler(ttlMap))
+		defer ttlServer.Close()
+
+		ttlMap.Put([]byte("session"), []byte("first"))
+		resp, _ := doRequest(t, ttlServer, http.MethodPut, "/keys/session?ttl=1m", "second", map[string]string{ "If-Match": cmaphttp.Digest([]byte("first")) })
+		if resp.StatusCode != http.StatusNoContent || string(ttlMap.Get([]byte("session"))) != "second" { t.Fatalf("matching if match should swap, got %d", resp.StatusCode) }
+
+		clock.Advance(2 * time.Minute)
+		if ttlMap.Get([]byte("session")) != nil { t.Error("expected the conditional put to keep its ttl") }
+	})
+
+	t.Run("test delete if match any on missing key", func(t *testing.T) {
+		resp, _ := doRequest(t, server, http.MethodDelete, "/keys/missing", "", map[string]string{ "If-Match": "*" })
+		if resp.StatusCode != http.StatusPreconditionFailed { t.Errorf("if match * should fail for a missing key, got %d", resp.StatusCode) }
+	})
+
+	t.Run("test body errors", func(t *testing.T) {
+		handler := cmaphttp.NewHandler(cMap)
+		handler.MaxBodyBytes = 8
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/keys/large", strings.NewReader("more than eight bytes")))
+		if recorder.Code != http.StatusRequestEntityTooLarge { t.Errorf("expected request entity too large, got %d", recorder.Code) }
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/keys/broken", iotest.ErrReader(errors.New("connection reset"))))
+		if recorder.Code != http.StatusBadRequest { t.Errorf("expected bad request for a failed read, got %d", recorder.Code) }
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/batch/put", strings.NewReader(`[{"key": "a", "value": "more than eight bytes"}]`)))
+		if recorder.Code != http.StatusRequestEntityTooLarge { t.Errorf("expected request entity too large for a batch, got %d", recorder.Code) }
+	})
+
+	t.Run("test batch", func(t *testing.T) {
+		resp, body := doRequest(t, server, http.MethodPost, "/batch/put", `{"entries":[{"key":"a","value":"1"},{"key":"b","value":"2"}]}`, nil)
+		if resp.StatusCode != http.StatusOK || ! strings.Contains(body, `"written":2`) { t.Errorf("unexpected batch put response: %d, %s", resp.StatusCode, body) }
+
+		_, body = doRequest(t, server, http.MethodPost, "/batch/get", `{"keys":["a","missing","b"]}`, nil)
+		var got struct { Entries []struct { Key string; Value *string } }
+		if err := json.Unmarshal([]byte(body), &got); err != nil { t.Fatal(err) }
+		if len(got.Entries) != 3 || *got.Entries[0].Value != "1" || got.Entries[1].Value != nil || *got.Entries[2].Value != "2" { t.Errorf("unexpected batch get response: %s", body) }
+
+		_, body = doRequest(t, server, http.MethodPost, "/batch/delete", `{"keys":["a","missing","b"]}`, nil)
+		if ! strings.Contains(body, `"deleted":2`) { t.Errorf("unexpected batch delete response: %s", body) }
+	})
+
+	t.Run("test streaming list", func(t *testing.T) {
+		for i := 0; i < 1000; i++ { cMap.Put([]byte(fmt.Sprintf("list:%d", i)), []byte("value")) }
+		cMap.Put([]byte("other"), []byte("value"))
+
+		resp, err := http.Get(server.URL + "/keys?prefix=list:")
+		if err != nil { t.Fatal(err) }
+		defer resp.Body.Close()
+
+		lines := 0
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var line struct { Key string; Value *string }
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil { t.Fatal(err) }
+			if ! strings.HasPrefix(line.Key, "list:") || line.Value == nil || *line.Value != "value" { t.Errorf("unexpected line: %s", scanner.Text()) }
+			lines++
+		}
+
+		if lines != 1000 { t.Errorf("actual lines not equal to expected: actual(%d), expected(%d)", lines, 1000) }
+
+		_, body := doRequest(t, server, http.MethodGet, "/keys?limit=10&values=false", "", nil)
+		if strings.Count(body, "\n") != 10 || strings.Contains(body, `"value":"`) { t.Errorf("unexpected limited list: %s", body) }
+	})
+
+	t.Run("test stats", func(t *testing.T) {
+		cMap.Metrics = cmap.NewCMapMetrics()
+		defer func() { cMap.Metrics = nil }()
+
+		_, body := doRequest(t, server, http.MethodGet, "/stats", "", nil)
+
+		var stats map[string]any
+		if err := json.Unmarshal([]byte(body), &stats); err != nil { t.Fatal(err) }
+		if stats["len"] != float64(cMap.Len()) || stats["structure"] == nil || stats["metrics"] == nil { t.Errorf("unexpected stats: %s", body) }
+
+		resp, _ := doRequest(t, server, http.MethodPost, "/stats", "", nil)
+		if resp.StatusCode != http.StatusMethodNotAllowed { t.Errorf("expected method not allowed, got %d", resp.StatusCode) }
+	})
+}
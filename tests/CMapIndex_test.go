@@ -0,0 +1,134 @@
+package cmaptests
+
+import "fmt"
+import "sort"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapOrderedIndex(t *testing.T) {
+	collect := func(scan func(fn func(key, value []byte) bool) error) []string {
+		keys := []string{}
+		err := scan(func(key, value []byte) bool {
+			keys = append(keys, string(key))
+			return true
+		})
+
+		if err != nil { t.Fatal(err) }
+		return keys
+	}
+
+	t.Run("test not enabled", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		if err := cMap.RangeScan(nil, nil, func(key, value []byte) bool { return true }); err != cmap.ErrNotIndexed { t.Errorf("expected ErrNotIndexed, got %v", err) }
+		if _, _, err := cMap.Min(); err != cmap.ErrNotIndexed { t.Errorf("expected ErrNotIndexed, got %v", err) }
+	})
+
+	t.Run("test scans", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		for i := 0; i < 500; i++ { cMap.Put([]byte(fmt.Sprintf("user:%03d", i)), []byte(fmt.Sprintf("value%d", i))) }
+
+		cMap.EnableOrderedIndex()
+		for i := 500; i < 1000; i++ { cMap.Put([]byte(fmt.Sprintf("user:%03d", i)), []byte(fmt.Sprintf("value%d", i))) }
+		for i := 0; i < 1000; i += 3 { cMap.Delete([]byte(fmt.Sprintf("user:%03d", i))) }
+		cMap.Put([]byte("order:1"), []byte("value"))
+
+		if err := cMap.Verify(); err != nil { t.Fatal(err) }
+
+		all := collect(func(fn func(key, value []byte) bool) error { return cMap.RangeScan(nil, nil, fn) })
+		if len(all) != cMap.Len() || ! sort.StringsAreSorted(all) { t.Errorf("expected every key in order, got %d of %d", len(all), cMap.Len()) }
+
+		ranged := collect(func(fn func(key, value []byte) bool) error { return cMap.RangeScan([]byte("user:100"), []byte("user:110"), fn) })
+		if fmt.Sprint(ranged) != "[user:100 user:101 user:103 user:104 user:106 user:107 user:109]" { t.Errorf("unexpected range: %v", ranged) }
+
+		reversed := collect(func(fn func(key, value []byte) bool) error { return cMap.ReverseRangeScan([]byte("user:100"), []byte("user:110"), fn) })
+		if fmt.Sprint(reversed) != "[user:109 user:107 user:106 user:104 user:103 user:101 user:100]" { t.Errorf("unexpected reverse range: %v", reversed) }
+
+		prefixed := collect(func(fn func(key, value []byte) bool) error { return cMap.PrefixScan([]byte("user:99"), fn) })
+		if fmt.Sprint(prefixed) != "[user:991 user:992 user:994 user:995 user:997 user:998]" { t.Errorf("unexpected prefix scan: %v", prefixed) }
+
+		minKey, _, _ := cMap.Min()
+		maxKey, maxValue, _ := cMap.Max()
+		if string(minKey) != "order:1" || string(maxKey) != "user:998" || string(maxValue) != "value998" { t.Errorf("unexpected min and max: %s, %s", minKey, maxKey) }
+	})
+
+	t.Run("test views keep their index", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.EnableOrderedIndex()
+		cMap.Put([]byte("a"), []byte("1"))
+
+		view := cMap.View()
+		cMap.Put([]byte("b"), []byte("2"))
+
+		if keys := collect(func(fn func(key, value []byte) bool) error { return view.RangeScan(nil, nil, fn) }); len(keys) != 1 { t.Errorf("view should not see later keys: %v", keys) }
+		if keys := collect(func(fn func(key, value []byte) bool) error { return cMap.PMap().Without([]byte("a")).CMap().RangeScan(nil, nil, fn) }); fmt.Sprint(keys) != "[b]" { t.Errorf("unexpected keys after conversion: %v", keys) }
+	})
+
+	t.Run("test retained versions keep their index", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.RetainVersions(4)
+
+		events := make(chan cmap.CMapEvent, 4)
+		stop := cMap.OnChange(func(event cmap.CMapEvent) { events <- event })
+		defer stop()
+
+		cMap.Put([]byte("a"), []byte("1"))
+		seq := cMap.Seq()
+
+		cMap.EnableOrderedIndex()
+		if cMap.Seq() != seq + 1 { t.Errorf("expected enabling the index to publish a new version, got %d after %d", cMap.Seq(), seq) }
+
+		view, err := cMap.ViewAt(cMap.Seq())
+		if err != nil { t.Fatal(err) }
+		if keys := collect(func(fn func(key, value []byte) bool) error { return view.RangeScan(nil, nil, fn) }); fmt.Sprint(keys) != "[a]" { t.Errorf("unexpected keys at the indexed version: %v", keys) }
+
+		cMap.Put([]byte("b"), []byte("2"))
+		for _, expected := range []string{ "a", "b" } {
+			if event := <- events; string(event.Key) != expected { t.Errorf("unexpected event for %s, expected %s", event.Key, expected) }
+		}
+	})
+
+	t.Run("test enabling the index keeps the change log", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		if err := cMap.EnableChangeLog(cmap.CMapChangeLogOptions{}); err != nil { t.Fatal(err) }
+		start := cMap.Seq()
+
+		cMap.Put([]byte("a"), []byte("1"))
+		cMap.EnableOrderedIndex()
+		cMap.Put([]byte("b"), []byte("2"))
+
+		changes, err := cMap.ChangesSince(start)
+		if err != nil || len(changes) != 2 { t.Fatalf("expected the changes on both sides of the index, got %+v, %v", changes, err) }
+
+		tail, err := cMap.ChangesSince(changes[0].Seq)
+		if err != nil || len(tail) != 1 || string(tail[0].Key) != "b" { t.Errorf("expected the change after the index, got %+v, %v", tail, err) }
+
+		if keys := collect(func(fn func(key, value []byte) bool) error { return cMap.RangeScan(nil, nil, fn) }); fmt.Sprint(keys) != "[a b]" { t.Errorf("unexpected keys: %v", keys) }
+	})
+
+	t.Run("test concurrent writes", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.EnableOrderedIndex()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					key := []byte(fmt.Sprintf("%d-%d", w, i))
+					cMap.Put(key, []byte("value"))
+					if i % 2 == 0 { cMap.Delete(key) }
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		keys := collect(func(fn func(key, value []byte) bool) error { return cMap.RangeScan(nil, nil, fn) })
+		if len(keys) != 2000 || ! sort.StringsAreSorted(keys) { t.Errorf("expected 2000 sorted keys, got %d", len(keys)) }
+		if err := cMap.Verify(); err != nil { t.Error(err) }
+	})
+}
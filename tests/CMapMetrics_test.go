@@ -0,0 +1,135 @@
+package cmaptests
+
+import "bytes"
+import "context"
+import "encoding/json"
+import "expvar"
+import "strings"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+import "github.com/sirgallo/cmap/cmapmetrics"
+
+
+func TestCMapMetrics(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+	metrics := cmap.NewCMapMetrics()
+	cMap.Metrics = metrics
+
+	inputSize := 10000
+	keyVals := make([]KeyVal, inputSize)
+
+	for idx := range keyVals {
+		randomBytes, _ := GenerateRandomBytes(32)
+		keyVals[idx] = KeyVal{ Key: randomBytes, Value: randomBytes }
+	}
+
+	t.Run("test concurrent ops are counted", func(t *testing.T) {
+		var opWG sync.WaitGroup
+
+		for _, val := range keyVals {
+			opWG.Add(1)
+			go func(val KeyVal) {
+				defer opWG.Done()
+
+				cMap.Put(val.Key, val.Value)
+				cMap.Get(val.Key)
+			}(val)
+		}
+
+		opWG.Wait()
+
+		putMetrics := &metrics.Ops[cmap.PutOp]
+		t.Logf("puts: %d, cas failures: %d, retries: %d", putMetrics.Count.Load(), putMetrics.CASFailures.Load(), putMetrics.Retries.Load())
+
+		if putMetrics.Count.Load() != uint64(inputSize) {
+			t.Errorf("put count does not match expected: actual(%d), expected(%d)", putMetrics.Count.Load(), inputSize)
+		}
+
+		if metrics.Ops[cmap.GetOp].Count.Load() != uint64(inputSize) {
+			t.Errorf("get count does not match expected: actual(%d), expected(%d)", metrics.Ops[cmap.GetOp].Count.Load(), inputSize)
+		}
+
+		if putMetrics.CASFailures.Load() != putMetrics.Retries.Load() {
+			t.Errorf("every cas failure should be a retry: failures(%d), retries(%d)", putMetrics.CASFailures.Load(), putMetrics.Retries.Load())
+		}
+
+		infBucket := putMetrics.RetryBuckets[len(cmap.RetryBucketBounds)].Load()
+		if infBucket != uint64(inputSize) || putMetrics.LatencyBuckets[len(cmap.LatencyBucketBounds)].Load() != uint64(inputSize) {
+			t.Errorf("+Inf buckets should contain every operation: actual(%d), expected(%d)", infBucket, inputSize)
+		}
+	})
+
+	t.Run("test prometheus export", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := cmapmetrics.WritePrometheus(&buf, "cmap", metrics)
+		if err != nil { t.Fatalf("error writing prometheus metrics: %s", err) }
+
+		output := buf.String()
+		expectedLines := []string{
+			"# TYPE cmap_operations_total counter",
+			"cmap_operations_total{op=\"put\"} 10000",
+			"cmap_operations_total{op=\"get\"} 10000",
+			"cmap_retries_exhausted_total{op=\"put\"} 0",
+			"cmap_cancelled_total{op=\"put\"} 0",
+			"cmap_operation_retries_bucket{op=\"put\",le=\"+Inf\"} 10000",
+			"cmap_operation_latency_seconds_count{op=\"get\"} 10000",
+		}
+
+		for _, line := range expectedLines {
+			if ! strings.Contains(output, line) { t.Errorf("prometheus output missing line: %s", line) }
+		}
+	})
+
+	t.Run("test expvar export", func(t *testing.T) {
+		cmapmetrics.PublishExpvar("cmap_test_metrics", metrics)
+
+		var snapshot map[string]map[string]any
+		err := json.Unmarshal([]byte(expvar.Get("cmap_test_metrics").String()), &snapshot)
+		if err != nil { t.Fatalf("error decoding expvar metrics: %s", err) }
+
+		if snapshot["put"]["count"].(float64) != float64(inputSize) {
+			t.Errorf("expvar put count does not match expected: actual(%v), expected(%d)", snapshot["put"]["count"], inputSize)
+		}
+	})
+
+	t.Run("test failed ops are counted separately", func(t *testing.T) {
+		failMap := cmap.NewCMap[uint32]()
+		failMetrics := cmap.NewCMapMetrics()
+		failMap.Metrics = failMetrics
+		failMap.MaxRetries = 2
+
+		contended := cmap.CMapPutOptions{ 
+			Match: func(value []byte) bool {
+				failMap.Put([]byte("other"), []byte("value"))
+				return true
+			},
+		}
+
+		failMap.Put([]byte("key"), []byte("value"))
+		_, err := failMap.PutWithOptions(context.Background(), []byte("key"), []byte("updated"), contended)
+		if err != cmap.ErrMaxRetriesExceeded { t.Fatalf("expected max retries exceeded, got %v", err) }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := failMap.PutContext(ctx, []byte("key"), []byte("updated")); err != context.Canceled { t.Fatalf("expected context cancelled, got %v", err) }
+		if err := failMap.DeleteContext(ctx, []byte("key")); err != context.Canceled { t.Fatalf("expected context cancelled, got %v", err) }
+
+		putMetrics := &failMetrics.Ops[cmap.PutOp]
+		if putMetrics.RetriesExhausted.Load() != 1 || putMetrics.Cancelled.Load() != 1 {
+			t.Errorf("unexpected put failures: exhausted(%d), cancelled(%d)", putMetrics.RetriesExhausted.Load(), putMetrics.Cancelled.Load())
+		}
+
+		if failMetrics.Ops[cmap.DeleteOp].Cancelled.Load() != 1 || failMetrics.Ops[cmap.DeleteOp].Count.Load() != 0 {
+			t.Errorf("expected the cancelled delete to not be counted as completed: count(%d)", failMetrics.Ops[cmap.DeleteOp].Count.Load())
+		}
+
+		// the initial put and the put on every attempt of the contended put complete, the contended and cancelled puts do not
+		if putMetrics.Count.Load() != 4 { t.Errorf("put count does not match expected: actual(%d), expected(4)", putMetrics.Count.Load()) }
+		if putMetrics.RetryBuckets[len(cmap.RetryBucketBounds)].Load() != 4 { t.Error("expected failed puts to be left out of the histograms") }
+	})
+
+	t.Log("Done")
+}
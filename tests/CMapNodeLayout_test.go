@@ -0,0 +1,105 @@
+package cmaptests
+
+import "bytes"
+import "encoding/binary"
+import "runtime"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapNodeLayout(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+
+	t.Run("test inline and referenced leaves", func(t *testing.T) {
+		sizes := []struct{ keySize, valueSize int }{ { 1, 0 }, { 8, 8 }, { 15, 15 }, { 15, 16 }, { 32, 64 } }
+		keyVals := make([]KeyVal, len(sizes))
+
+		for idx, size := range sizes {
+			key, _ := GenerateRandomBytes(size.keySize)
+			value, _ := GenerateRandomBytes(size.valueSize)
+			keyVals[idx] = KeyVal{ Key: key, Value: value }
+
+			cMap.Put(key, value)
+		}
+
+		for _, val := range keyVals {
+			value := cMap.Get(val.Key)
+			if ! bytes.Equal(value, val.Value) {
+				t.Errorf("actual value not equal to expected: actual(%v), expected(%v)", value, val.Value)
+			}
+		}
+	})
+
+	t.Run("test appending to an inline value does not modify the leaf", func(t *testing.T) {
+		cMap.Put([]byte("key"), []byte("value"))
+
+		value := cMap.Get([]byte("key"))
+		_ = append(value, []byte("appended")...)
+
+		if string(cMap.Get([]byte("key"))) != "value" {
+			t.Errorf("leaf modified by append: actual(%s), expected(value)", cMap.Get([]byte("key")))
+		}
+	})
+
+	t.Run("test stats for leaf layouts", func(t *testing.T) {
+		stats := cMap.Stats()
+		if stats.LeafCount != 6 {
+			t.Errorf("leaf count does not match expected: actual(%d), expected(6)", stats.LeafCount)
+		}
+	})
+}
+
+
+//=================================== Benchmarks
+
+// run with go test -run XXX -bench MemoryPerEntry ./tests
+//	reports the heap retained by the map per entry, including the key and value buffers referenced by leaves
+//	on a single core with 100,000 entries:
+//		old layout, a single node type carrying Key, Value, IsLeaf, Bitmap and Children: Small32 135.3, Large32 215.1, Small64 142.4, Large64 222.6 B/entry
+//		new layout, separate internal, leaf and inline leaf nodes: Small32 68.8, Large32 180.7, Small64 62.2, Large64 174.3 B/entry
+
+func BenchmarkMemoryPerEntrySmall32(b *testing.B) {
+	benchmarkMemoryPerEntry(b, cmap.NewCMap[uint32], 8, 8)
+}
+
+func BenchmarkMemoryPerEntryLarge32(b *testing.B) {
+	benchmarkMemoryPerEntry(b, cmap.NewCMap[uint32], 32, 64)
+}
+
+func BenchmarkMemoryPerEntrySmall64(b *testing.B) {
+	benchmarkMemoryPerEntry(b, cmap.NewCMap[uint64], 8, 8)
+}
+
+func BenchmarkMemoryPerEntryLarge64(b *testing.B) {
+	benchmarkMemoryPerEntry(b, cmap.NewCMap[uint64], 32, 64)
+}
+
+func benchmarkMemoryPerEntry[T uint32 | uint64](b *testing.B, newMap func() *cmap.CMap[T], keySize int, valueSize int) {
+	entries := 100000
+	var memStats runtime.MemStats
+	var bytesPerEntry float64
+
+	for idx := 0; idx < b.N; idx++ {
+		runtime.GC()
+		runtime.ReadMemStats(&memStats)
+		before := memStats.HeapAlloc
+
+		cMap := newMap()
+		for entry := 0; entry < entries; entry++ {
+			key := make([]byte, keySize)
+			value := make([]byte, valueSize)
+			binary.BigEndian.PutUint64(key, uint64(entry))
+			
+			cMap.Put(key, value)
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&memStats)
+		bytesPerEntry = float64(memStats.HeapAlloc - before) / float64(entries)
+		
+		runtime.KeepAlive(cMap)
+	}
+
+	b.ReportMetric(bytesPerEntry, "B/entry")
+}
@@ -0,0 +1,125 @@
+package cmaptests
+
+import "context"
+import "errors"
+import "fmt"
+import "strconv"
+import "sync"
+import "sync/atomic"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapParallelRange(t *testing.T) {
+	t.Run("test parallel range", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		for i := 0; i < 10000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		var mu sync.Mutex
+		seen := make(map[string]int)
+		err := cMap.ParallelRange(context.Background(), 8, func(key, value []byte) error {
+			mu.Lock()
+			seen[string(key)]++
+			mu.Unlock()
+
+			return nil
+		})
+
+		if err != nil { t.Fatal(err) }
+		if len(seen) != 10000 { t.Errorf("expected 10000 keys, got %d", len(seen)) }
+		for key, times := range seen {
+			if times != 1 { t.Errorf("key %s visited %d times", key, times) }
+		}
+	})
+
+	t.Run("test snapshot", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 5000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		view := cMap.View()
+		for i := 5000; i < 6000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		visited := int64(0)
+		err := view.ParallelRange(context.Background(), 0, func(key, value []byte) error {
+			atomic.AddInt64(&visited, 1)
+			return nil
+		})
+
+		if err != nil { t.Fatal(err) }
+		if visited != 5000 { t.Errorf("expected 5000 keys in the view, got %d", visited) }
+	})
+
+	t.Run("test error propagation", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 10000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		errStop := errors.New("stop")
+		visited := int64(0)
+		err := cMap.ParallelRange(context.Background(), 4, func(key, value []byte) error {
+			if atomic.AddInt64(&visited, 1) == 100 { return errStop }
+			return nil
+		})
+
+		if err != errStop { t.Errorf("expected errStop, got %v", err) }
+		if visited >= 10000 { t.Errorf("expected the range to stop early, visited %d", visited) }
+	})
+
+	t.Run("test cancellation", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		for i := 0; i < 10000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		visited := int64(0)
+		err := cMap.ParallelRange(ctx, 4, func(key, value []byte) error {
+			if atomic.AddInt64(&visited, 1) == 100 { cancel() }
+			return nil
+		})
+
+		if err != context.Canceled { t.Errorf("expected context.Canceled, got %v", err) }
+		if visited >= 10000 { t.Errorf("expected the range to stop early, visited %d", visited) }
+
+		_, err = cmap.MapReduce(ctx, cMap, 4, func(key, value []byte) (int, error) { return 1, nil }, func(acc, result int) int { return acc + result })
+		if err != context.Canceled { t.Errorf("expected context.Canceled, got %v", err) }
+	})
+
+	t.Run("test cancellation after the last key", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		for i := 0; i < 1000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		visited := 0
+		err := cMap.ParallelRange(ctx, 1, func(key, value []byte) error {
+			if visited++; visited == cMap.Len() { cancel() }
+			return nil
+		})
+
+		if err != nil || visited != 1000 { t.Errorf("expected every key visited without an error, got %d, %v", visited, err) }
+	})
+
+	t.Run("test map reduce", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		expected := 0
+		for i := 0; i < 10000; i++ {
+			cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte(strconv.Itoa(i)))
+			expected += i
+		}
+
+		sum, err := cmap.MapReduce(context.Background(), cMap, 8, func(key, value []byte) (int, error) {
+			return strconv.Atoi(string(value))
+		}, func(acc, result int) int { return acc + result })
+
+		if err != nil { t.Fatal(err) }
+		if sum != expected { t.Errorf("actual sum not equal to expected: actual(%d), expected(%d)", sum, expected) }
+
+		cMap.Put([]byte("bad"), []byte("not a number"))
+		_, err = cmap.MapReduce(context.Background(), cMap, 8, func(key, value []byte) (int, error) {
+			return strconv.Atoi(string(value))
+		}, func(acc, result int) int { return acc + result })
+
+		if err == nil { t.Error("expected the map error to propagate") }
+
+		empty, err := cmap.MapReduce(context.Background(), cmap.NewCMap[uint64](), 8, func(key, value []byte) (int, error) { return 1, nil }, func(acc, result int) int { return acc + result })
+		if err != nil || empty != 0 { t.Errorf("expected 0 for an empty map, got %d, %v", empty, err) }
+	})
+}
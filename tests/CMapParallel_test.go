@@ -1,7 +1,10 @@
 package cmaptests
 
 import "bytes"
+import "context"
+import "errors"
 import "sync"
+import "sync/atomic"
 import "testing"
 
 import "github.com/sirgallo/cmap"
@@ -85,4 +88,98 @@ func TestParallelReadWrites(t *testing.T) {
 	})
 
 	t.Log("Done")
-}
\ No newline at end of file
+}
+
+
+func TestParallelPutsAndDeletes(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+
+	for _, val := range initKeyValPairs { cMap.Put(val.Key, val.Value) }
+
+	t.Run("test concurrent deletes and inserts in shared subtrees", func(t *testing.T) {
+		var opWG sync.WaitGroup
+
+		for idx := range pKeyValPairs {
+			opWG.Add(2)
+			go func(val KeyVal) {
+				defer opWG.Done()
+				
+				cMap.Delete(val.Key)
+			}(initKeyValPairs[idx])
+
+			go func(val KeyVal) {
+				defer opWG.Done()
+
+				cMap.Put(val.Key, val.Value)
+			}(pKeyValPairs[idx])
+		}
+
+		opWG.Wait()
+
+		for idx := range pKeyValPairs {
+			if cMap.Get(initKeyValPairs[idx].Key) != nil { t.Errorf("deleted key still present: %v", initKeyValPairs[idx].Key) }
+
+			value := cMap.Get(pKeyValPairs[idx].Key)
+			if ! bytes.Equal(value, pKeyValPairs[idx].Value) {
+				t.Errorf("actual value not equal to expected: actual(%s), expected(%s)", value, pKeyValPairs[idx].Value)
+			}
+		}
+
+		stats := cMap.Stats()
+		if stats.LeafCount != len(pKeyValPairs) {
+			t.Errorf("leaf count does not match expected: actual(%d), expected(%d)", stats.LeafCount, len(pKeyValPairs))
+		}
+	})
+}
+
+
+//=================================== Benchmarks
+
+// run with go test -bench=Parallel -cpu=1,4,16 ./tests to compare retry cost as write concurrency increases
+// each benchmark runs a failed level sub-benchmark, retrying from the level where the conflict occurred, and a full path sub-benchmark, 
+// where the first failed compare and swap aborts the put so it is rerun with a new path from the root, as before retries kept the copied path
+
+// errRetryFromRoot aborts a put on its first failed compare and swap
+var errRetryFromRoot = errors.New("retry from root")
+
+// fullPathBackoff aborts the retry loop instead of waiting, so the put is rerun from the root
+type fullPathBackoff struct{}
+
+func (fullPathBackoff) Wait(ctx context.Context, attempt int) error { return errRetryFromRoot }
+
+
+func BenchmarkParallelWrites(b *testing.B) {
+	benchmarkRetryStrategies(b, pKeyValPairs)
+}
+
+func BenchmarkParallelHotKeyWrites(b *testing.B) {
+	benchmarkRetryStrategies(b, pKeyValPairs[:64])
+}
+
+func benchmarkRetryStrategies(b *testing.B, keyVals []KeyVal) {
+	b.Run("failed level", func(b *testing.B) { benchmarkParallelWrites(b, keyVals, false) })
+	b.Run("full path", func(b *testing.B) { benchmarkParallelWrites(b, keyVals, true) })
+}
+
+func benchmarkParallelWrites(b *testing.B, keyVals []KeyVal, fullPath bool) {
+	cMap := cmap.NewCMap[uint32]()
+	for _, val := range initKeyValPairs { cMap.Put(val.Key, val.Value) }
+
+	metrics := cmap.NewCMapMetrics()
+	cMap.Metrics = metrics
+	if fullPath { cMap.Backoff = fullPathBackoff{} }
+
+	var counter atomic.Uint64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			val := keyVals[counter.Add(1) % uint64(len(keyVals))]
+			for cMap.PutContext(context.Background(), val.Key, val.Value) == errRetryFromRoot {}
+		}
+	})
+
+	b.ReportMetric(float64(metrics.Ops[cmap.PutOp].CASFailures.Load()) / float64(b.N), "casfailures/op")
+}
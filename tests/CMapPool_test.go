@@ -0,0 +1,74 @@
+package cmaptests
+
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+var poolKeyValPairs []KeyVal
+
+
+func init() {
+	poolKeyValPairs = make([]KeyVal, 100000)
+
+	for idx := range poolKeyValPairs {
+		randomBytes, _ := GenerateRandomBytes(32)
+		poolKeyValPairs[idx] = KeyVal{ Key: randomBytes, Value: randomBytes }
+	}
+}
+
+
+func TestCMapPutAllocations(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+	for _, val := range poolKeyValPairs { cMap.Put(val.Key, val.Value) }
+
+	t.Run("test update allocations", func(t *testing.T) {
+		idx := 0
+		allocs := testing.AllocsPerRun(10000, func() {
+			val := poolKeyValPairs[idx % len(poolKeyValPairs)]
+			cMap.Put(val.Key, val.Value)
+			idx++
+		})
+
+		t.Logf("allocs per update: %f", allocs)
+		if allocs >= 12 { t.Errorf("update allocations should be below the 12 allocs/op before pooling: actual(%f)", allocs) }
+	})
+
+	t.Run("test delete allocations", func(t *testing.T) {
+		idx := 0
+		allocs := testing.AllocsPerRun(10000, func() {
+			cMap.Delete(poolKeyValPairs[idx % len(poolKeyValPairs)].Key)
+			idx++
+		})
+
+		t.Logf("allocs per delete: %f", allocs)
+		if allocs >= 12 { t.Errorf("delete allocations should be below the 12 allocs/op before pooling: actual(%f)", allocs) }
+	})
+}
+
+
+//=================================== Benchmarks
+
+// run with go test -bench=Put -benchmem ./tests
+//	on a single core with 100,000 keys, before pooling: BenchmarkPut32 12 allocs/op, 1559 B/op and BenchmarkPut64 8 allocs/op, 1847 B/op
+//	after pooling: BenchmarkPut32 9 allocs/op, 1418 B/op and BenchmarkPut64 8 allocs/op, 1827 B/op
+
+func BenchmarkPut32(b *testing.B) {
+	benchmarkPut(b, cmap.NewCMap[uint32]())
+}
+
+func BenchmarkPut64(b *testing.B) {
+	benchmarkPut(b, cmap.NewCMap[uint64]())
+}
+
+func benchmarkPut[T uint32 | uint64](b *testing.B, cMap *cmap.CMap[T]) {
+	for _, val := range poolKeyValPairs[:len(poolKeyValPairs) / 2] { cMap.Put(val.Key, val.Value) }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		val := poolKeyValPairs[idx % len(poolKeyValPairs)]
+		cMap.Put(val.Key, val.Value)
+	}
+}
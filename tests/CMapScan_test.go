@@ -0,0 +1,87 @@
+package cmaptests
+
+import "fmt"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapScan(t *testing.T) {
+	scanAll := func(scan func(cursor uint64, count int) ([][]byte, uint64), count int) (map[string]int, int) {
+		seen := make(map[string]int)
+		pages := 0
+		cursor := uint64(0)
+		for {
+			keys, nextCursor := scan(cursor, count)
+			for _, key := range keys { seen[string(key)]++ }
+
+			pages++
+			cursor = nextCursor
+			if cursor == 0 { return seen, pages }
+		}
+	}
+
+	t.Run("test empty", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		keys, cursor := cMap.Scan(0, 10)
+		if len(keys) != 0 || cursor != 0 { t.Errorf("expected an empty complete scan, got %d keys and cursor %d", len(keys), cursor) }
+	})
+
+	t.Run("test 32 bit", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 5000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		seen, pages := scanAll(cMap.Scan, 100)
+		if len(seen) != 5000 { t.Errorf("expected 5000 keys, got %d", len(seen)) }
+		for key, times := range seen {
+			if times != 1 { t.Errorf("key %s returned %d times", key, times) }
+		}
+
+		if pages < 5000 / 200 || pages > 5000 / 100 + 1 { t.Errorf("unexpected number of pages: %d", pages) }
+	})
+
+	t.Run("test 64 bit", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		for i := 0; i < 5000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		seen, _ := scanAll(cMap.Scan, 1)
+		if len(seen) != 5000 { t.Errorf("expected 5000 keys, got %d", len(seen)) }
+		for key, times := range seen {
+			if times != 1 { t.Errorf("key %s returned %d times", key, times) }
+		}
+	})
+
+	t.Run("test concurrent writes", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 2000; i++ { cMap.Put([]byte(fmt.Sprintf("stable%d", i)), []byte("value")) }
+		for i := 0; i < 2000; i++ { cMap.Put([]byte(fmt.Sprintf("churn%d", i)), []byte("value")) }
+
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; ; round++ {
+				select {
+					case <-done:
+						return
+					default:
+				}
+
+				for i := 0; i < 2000; i++ { cMap.Delete([]byte(fmt.Sprintf("churn%d", i))) }
+				for i := 0; i < 2000; i++ { cMap.Put([]byte(fmt.Sprintf("churn%d", i)), []byte(fmt.Sprintf("value%d", round))) }
+				for i := 0; i < 200; i++ { cMap.Put([]byte(fmt.Sprintf("new%d-%d", round, i)), []byte("value")) }
+			}
+		}()
+
+		seen, _ := scanAll(cMap.Scan, 50)
+		close(done)
+		wg.Wait()
+
+		for i := 0; i < 2000; i++ {
+			key := fmt.Sprintf("stable%d", i)
+			if seen[key] != 1 { t.Errorf("stable key %s returned %d times", key, seen[key]) }
+		}
+	})
+}
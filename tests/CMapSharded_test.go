@@ -0,0 +1,83 @@
+package cmaptests
+
+import "bytes"
+import "fmt"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestShardedCMap(t *testing.T) {
+	t.Run("test shard count", func(t *testing.T) {
+		for _, tc := range []struct{ shards, expected int }{ { 0, 1 }, { 1, 1 }, { 3, 4 }, { 16, 16 }, { 17, 32 } } {
+			shardedMap := cmap.NewShardedCMap[uint32](tc.shards)
+			if len(shardedMap.Shards) != tc.expected { t.Errorf("expected %d shards for %d, got %d", tc.expected, tc.shards, len(shardedMap.Shards)) }
+		}
+	})
+
+	t.Run("test operations", func(t *testing.T) {
+		shardedMap := cmap.NewShardedCMap[uint64](8)
+		for i := 0; i < 10000; i++ { shardedMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))) }
+
+		if shardedMap.Len() != 10000 { t.Errorf("expected 10000 entries, got %d", shardedMap.Len()) }
+		for i := 0; i < 10000; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if string(shardedMap.Get(key)) != fmt.Sprintf("value%d", i) { t.Fatalf("value mismatch for %s", key) }
+			if shardedMap.Shard(key).Get(key) == nil { t.Fatalf("%s not in its shard", key) }
+		}
+
+		for idx, shard := range shardedMap.Shards {
+			if shard.Len() < 10000 / 8 / 2 { t.Errorf("shard %d is unbalanced with %d entries", idx, shard.Len()) }
+			if err := shard.Verify(); err != nil { t.Error(err) }
+		}
+
+		if ! shardedMap.CompareAndSwap([]byte("key1"), []byte("value1"), []byte("swapped")) { t.Error("expected swap") }
+		if value, ok := shardedMap.LoadAndDelete([]byte("key1")); ! ok || string(value) != "swapped" { t.Errorf("unexpected load and delete: %s, %t", value, ok) }
+		shardedMap.Delete([]byte("key2"))
+		if shardedMap.Len() != 9998 || shardedMap.Get([]byte("key2")) != nil { t.Error("expected keys to be deleted") }
+
+		visited := 0
+		shardedMap.Range(func(key []byte, value []byte) bool {
+			visited++
+			return true
+		})
+
+		if visited != 9998 { t.Errorf("expected to visit 9998 entries, visited %d", visited) }
+	})
+
+	t.Run("test snapshot", func(t *testing.T) {
+		shardedMap := cmap.NewShardedCMap[uint32](4)
+		for i := 0; i < 1000; i++ { shardedMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))) }
+
+		var buf bytes.Buffer
+		if err := shardedMap.WriteSnapshot(&buf); err != nil { t.Fatal(err) }
+
+		loaded, err := cmap.ReadSnapshot[uint32](bytes.NewReader(buf.Bytes()))
+		if err != nil { t.Fatal(err) }
+		if loaded.Len() != 1000 || loaded.Bytes() != shardedMap.Bytes() { t.Errorf("unexpected loaded map: %d entries, %d bytes", loaded.Len(), loaded.Bytes()) }
+
+		resharded, err := cmap.ReadShardedSnapshot[uint32](bytes.NewReader(buf.Bytes()), 16)
+		if err != nil { t.Fatal(err) }
+		if resharded.Len() != 1000 { t.Errorf("expected 1000 entries, got %d", resharded.Len()) }
+		for i := 0; i < 1000; i++ {
+			if string(resharded.Get([]byte(fmt.Sprintf("key%d", i)))) != fmt.Sprintf("value%d", i) { t.Fatalf("value mismatch for key%d", i) }
+		}
+	})
+
+	t.Run("test concurrent writes", func(t *testing.T) {
+		shardedMap := cmap.NewShardedCMap[uint64](16)
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 2000; i++ { shardedMap.Put([]byte(fmt.Sprintf("%d-%d", w, i)), []byte("value")) }
+			}(w)
+		}
+
+		wg.Wait()
+		if shardedMap.Len() != 16000 { t.Errorf("expected 16000 entries, got %d", shardedMap.Len()) }
+	})
+}
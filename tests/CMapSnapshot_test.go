@@ -0,0 +1,143 @@
+package cmaptests
+
+import "bytes"
+import "encoding/binary"
+import "errors"
+import "fmt"
+import "hash/crc32"
+import "runtime"
+import "sync/atomic"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapSnapshot(t *testing.T) {
+	t.Run("test round trip", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 5000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))) }
+		cMap.Put([]byte("large"), bytes.Repeat([]byte("x"), 1000))
+		cMap.Put([]byte("empty"), []byte{})
+
+		var buf bytes.Buffer
+		if err := cMap.WriteSnapshot(&buf); err != nil { t.Fatal(err) }
+
+		bits, err := cmap.SnapshotBits(bytes.NewReader(buf.Bytes()))
+		if err != nil || bits != 32 { t.Errorf("unexpected snapshot bits: %d, %v", bits, err) }
+
+		loaded, err := cmap.ReadSnapshot[uint32](bytes.NewReader(buf.Bytes()))
+		if err != nil { t.Fatal(err) }
+
+		if loaded.Len() != cMap.Len() || loaded.Bytes() != cMap.Bytes() || loaded.Seq() != cMap.Seq() { t.Errorf("loaded map does not match: len(%d, %d), seq(%d, %d)", loaded.Len(), cMap.Len(), loaded.Seq(), cMap.Seq()) }
+		for i := 0; i < 5000; i++ {
+			if string(loaded.Get([]byte(fmt.Sprintf("key%d", i)))) != fmt.Sprintf("value%d", i) { t.Fatalf("value mismatch for key%d", i) }
+		}
+
+		if err := loaded.Verify(); err != nil { t.Error(err) }
+		if _, err := cmap.ReadSnapshot[uint64](bytes.NewReader(buf.Bytes())); err != cmap.ErrSnapshotBits { t.Errorf("expected ErrSnapshotBits, got %v", err) }
+	})
+
+	t.Run("test expiry is kept", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		cMap.PutWithTTL([]byte("short"), []byte("value"), time.Nanosecond)
+		cMap.PutWithTTL([]byte("long"), []byte("value"), time.Hour)
+
+		var buf bytes.Buffer
+		cMap.WriteSnapshot(&buf)
+		time.Sleep(time.Millisecond)
+
+		loaded, err := cmap.ReadSnapshot[uint64](&buf)
+		if err != nil { t.Fatal(err) }
+		if loaded.Len() != 1 || loaded.Get([]byte("long")) == nil { t.Error("only the unexpired key should be loaded") }
+	})
+
+	t.Run("test corrupt snapshots", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 100; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		var buf bytes.Buffer
+		cMap.WriteSnapshot(&buf)
+		snapshot := buf.Bytes()
+
+		flipped := append([]byte(nil), snapshot...)
+		flipped[len(flipped) / 2] ^= 0xff
+
+		for name, corrupt := range map[string][]byte{ "truncated": snapshot[:len(snapshot) - 10], "flipped": flipped, "magic": []byte("NOTASNAPSHOT") } {
+			if _, err := cmap.ReadSnapshot[uint32](bytes.NewReader(corrupt)); ! errors.Is(err, cmap.ErrInvalidSnapshot) { t.Errorf("%s: expected ErrInvalidSnapshot, got %v", name, err) }
+		}
+	})
+
+	t.Run("test corrupt lengths", func(t *testing.T) {
+		header := append([]byte("CMAPSNAP"), 1, 32)
+		header = binary.LittleEndian.AppendUint64(header, 0)
+
+		hugeCount := binary.AppendUvarint(append([]byte(nil), header...), 1 << 63)
+		if _, err := cmap.ReadSnapshot[uint32](bytes.NewReader(hugeCount)); ! errors.Is(err, cmap.ErrInvalidSnapshot) { t.Errorf("huge count: expected ErrInvalidSnapshot, got %v", err) }
+
+		hugeField := binary.AppendUvarint(append([]byte(nil), header...), 1)
+		hugeField = binary.AppendUvarint(append(hugeField, 0), 256 << 20)
+		hugeField = append(hugeField, []byte("short key")...)
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := cmap.ReadSnapshot[uint32](bytes.NewReader(hugeField))
+		runtime.ReadMemStats(&after)
+
+		if ! errors.Is(err, cmap.ErrInvalidSnapshot) { t.Errorf("huge field: expected ErrInvalidSnapshot, got %v", err) }
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16 << 20 { t.Errorf("expected a small allocation for a truncated field, allocated %d bytes", allocated) }
+	})
+}
+
+func TestCMapVerify(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+	for i := 0; i < 1000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+	for i := 0; i < 1000; i += 3 { cMap.Delete([]byte(fmt.Sprintf("key%d", i))) }
+
+	if err := cMap.Verify(); err != nil { t.Fatal(err) }
+
+	root := (*cmap.CMapNode[uint32])(atomic.LoadPointer(&cMap.Root))
+	root.Children[0], root.Children[1] = root.Children[1], root.Children[0]
+	if err := cMap.Verify(); ! errors.Is(err, cmap.ErrCorrupt) { t.Errorf("expected ErrCorrupt for misplaced children, got %v", err) }
+
+	root.Children[0], root.Children[1] = root.Children[1], root.Children[0]
+	if err := cMap.Verify(); err != nil { t.Fatal(err) }
+
+	root.Bitmap = cmap.SetBit(root.Bitmap, 0)
+
+	if err := cMap.Verify(); ! errors.Is(err, cmap.ErrCorrupt) { t.Errorf("expected ErrCorrupt for bitmap mismatch, got %v", err) }
+}
+
+func TestCMapVerifySnapshot(t *testing.T) {
+	clock := newTestClock()
+	cMap := cmap.NewCMap[uint64]()
+	cMap.Clock = clock
+	for i := 0; i < 100; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+	cMap.PutWithTTL([]byte("expiring"), []byte("value"), time.Minute)
+	clock.Advance(time.Hour)
+
+	var buf bytes.Buffer
+	if err := cMap.WriteSnapshot(&buf); err != nil { t.Fatal(err) }
+
+	entries, err := cmap.VerifySnapshot[uint64](bytes.NewReader(buf.Bytes()))
+	if err != nil || entries != 101 { t.Errorf("expected a valid snapshot with 101 entries, got %d, %v", entries, err) }
+
+	if _, err := cmap.VerifySnapshot[uint32](bytes.NewReader(buf.Bytes())); ! errors.Is(err, cmap.ErrSnapshotBits) { t.Errorf("expected ErrSnapshotBits, got %v", err) }
+
+	flipped := append([]byte(nil), buf.Bytes()...)
+	flipped[len(flipped) / 2] ^= 0xff
+	if _, err := cmap.VerifySnapshot[uint64](bytes.NewReader(flipped)); ! errors.Is(err, cmap.ErrInvalidSnapshot) { t.Errorf("expected ErrInvalidSnapshot for a checksum mismatch, got %v", err) }
+
+	duplicated := append([]byte("CMAPSNAP"), 1, 64)
+	duplicated = binary.LittleEndian.AppendUint64(duplicated, 2)
+	duplicated = binary.AppendUvarint(duplicated, 2)
+	for range []int{ 0, 1 } {
+		duplicated = append(duplicated, 0)
+		duplicated = append(binary.AppendUvarint(duplicated, 3), "key"...)
+		duplicated = append(binary.AppendUvarint(duplicated, 5), "value"...)
+	}
+
+	duplicated = binary.LittleEndian.AppendUint32(duplicated, crc32.Checksum(duplicated, crc32.MakeTable(crc32.Castagnoli)))
+	if _, err := cmap.ReadSnapshot[uint64](bytes.NewReader(duplicated)); err != nil { t.Fatalf("expected the duplicated snapshot to read, got %v", err) }
+	if _, err := cmap.VerifySnapshot[uint64](bytes.NewReader(duplicated)); ! errors.Is(err, cmap.ErrInvalidSnapshot) { t.Errorf("expected ErrInvalidSnapshot for duplicate keys, got %v", err) }
+}
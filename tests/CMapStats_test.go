@@ -0,0 +1,103 @@
+package cmaptests
+
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+//=================================== 32 bit
+
+func TestCMapStats32(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+
+	t.Run("test stats on empty map", func(t *testing.T) {
+		stats := cMap.Stats()
+		t.Logf("stats: %+v", stats)
+		if stats.LeafCount != 0 || stats.InternalNodeCount != 1 || stats.MaxDepth != 0 {
+			t.Errorf("empty map stats incorrect: leaves(%d), internal nodes(%d), max depth(%d)", stats.LeafCount, stats.InternalNodeCount, stats.MaxDepth)
+		}
+	})
+
+	inputSize := 10000
+	keyVals := make([]KeyVal, inputSize)
+
+	for idx := range keyVals {
+		randomBytes, _ := GenerateRandomBytes(32)
+		keyVals[idx] = KeyVal{ Key: randomBytes, Value: randomBytes }
+		cMap.Put(randomBytes, randomBytes)
+	}
+
+	t.Run("test stats after inserts", func(t *testing.T) {
+		stats := cMap.Stats()
+		t.Logf("leaves: %d, internal nodes: %d, max depth: %d, average depth: %f, reseeds: %d", stats.LeafCount, stats.InternalNodeCount, stats.MaxDepth, stats.AverageDepth, stats.Reseeds)
+		
+		if stats.LeafCount != inputSize {
+			t.Errorf("leaf count does not match expected: actual(%d), expected(%d)", stats.LeafCount, inputSize)
+		}
+
+		expectedBytes := uint64(inputSize * 32)
+		if stats.KeyBytes != expectedBytes || stats.ValueBytes != expectedBytes {
+			t.Errorf("key/value bytes do not match expected: actual(%d, %d), expected(%d)", stats.KeyBytes, stats.ValueBytes, expectedBytes)
+		}
+
+		if stats.AverageDepth < 1 || stats.AverageDepth > float64(stats.MaxDepth) {
+			t.Errorf("average depth out of range: actual(%f), max depth(%d)", stats.AverageDepth, stats.MaxDepth)
+		}
+
+		if stats.Levels[0].InternalNodes != 1 || len(stats.Levels[0].Fanout) != 33 {
+			t.Errorf("root level stats incorrect: %+v", stats.Levels[0])
+		}
+
+		totalLeaves, totalInternal := 0, 0
+		for _, levelStats := range stats.Levels {
+			totalLeaves += levelStats.Leaves
+			totalInternal += levelStats.InternalNodes
+
+			fanoutTotal := 0
+			for _, count := range levelStats.Fanout { fanoutTotal += count }
+			if fanoutTotal != levelStats.InternalNodes {
+				t.Errorf("fanout distribution does not sum to internal nodes at level %d: actual(%d), expected(%d)", levelStats.Level, fanoutTotal, levelStats.InternalNodes)
+			}
+		}
+
+		if totalLeaves != stats.LeafCount || totalInternal != stats.InternalNodeCount {
+			t.Errorf("level totals do not match: leaves(%d, %d), internal(%d, %d)", totalLeaves, stats.LeafCount, totalInternal, stats.InternalNodeCount)
+		}
+
+		if stats.NodeOverheadBytes == 0 { t.Error("node overhead should be estimated for a non empty map") }
+	})
+
+	t.Log("Done")
+}
+
+
+//=================================== 64 bit
+
+func TestCMapStats64(t *testing.T) {
+	cMap := cmap.NewCMap[uint64]()
+
+	inputSize := 10000
+	for range make([]int, inputSize) {
+		randomBytes, _ := GenerateRandomBytes(32)
+		cMap.Put(randomBytes, randomBytes)
+	}
+
+	t.Run("test stats after inserts", func(t *testing.T) {
+		stats := cMap.Stats()
+		t.Logf("leaves: %d, internal nodes: %d, max depth: %d, average depth: %f, reseeds: %d", stats.LeafCount, stats.InternalNodeCount, stats.MaxDepth, stats.AverageDepth, stats.Reseeds)
+
+		if stats.LeafCount != inputSize {
+			t.Errorf("leaf count does not match expected: actual(%d), expected(%d)", stats.LeafCount, inputSize)
+		}
+
+		if len(stats.Levels[0].Fanout) != 65 {
+			t.Errorf("root fanout distribution should have 65 buckets: actual(%d)", len(stats.Levels[0].Fanout))
+		}
+
+		if stats.Reseeds != (stats.MaxDepth - 1) / cMap.HashChunks {
+			t.Errorf("reseeds do not match max depth: reseeds(%d), max depth(%d)", stats.Reseeds, stats.MaxDepth)
+		}
+	})
+
+	t.Log("Done")
+}
@@ -0,0 +1,119 @@
+package cmaptests
+
+import "context"
+import "math"
+import "sync/atomic"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+type testClock struct {
+	now atomic.Int64
+}
+
+func newTestClock() *testClock {
+	clock := &testClock{}
+	clock.now.Store(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	
+	return clock
+}
+
+func (clock *testClock) Now() time.Time {
+	return time.Unix(0, clock.now.Load())
+}
+
+func (clock *testClock) Advance(duration time.Duration) {
+	clock.now.Add(int64(duration))
+}
+
+
+func TestCMapTTL(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+	clock := newTestClock()
+	cMap.Clock = clock
+
+	t.Run("test get before and after expiry", func(t *testing.T) {
+		cMap.PutWithTTL([]byte("session"), []byte("token"), time.Minute)
+
+		if string(cMap.Get([]byte("session"))) != "token" { t.Error("key should be present before expiry") }
+
+		clock.Advance(time.Minute)
+
+		if cMap.Get([]byte("session")) != nil { t.Error("key should be missing after expiry") }
+		if cMap.Stats().LeafCount != 0 { t.Error("expired leaf should be lazily deleted on get") }
+	})
+
+	t.Run("test put removes expiry", func(t *testing.T) {
+		cMap.PutWithTTL([]byte("key"), []byte("ttl"), time.Second)
+		cMap.Put([]byte("key"), []byte("no ttl"))
+
+		clock.Advance(time.Hour)
+
+		if string(cMap.Get([]byte("key"))) != "no ttl" { t.Error("put should replace the expiring leaf") }
+	})
+
+	t.Run("test zero ttl does not expire", func(t *testing.T) {
+		cMap.PutWithTTL([]byte("forever"), []byte("value"), 0)
+		clock.Advance(24 * time.Hour)
+
+		if string(cMap.Get([]byte("forever"))) != "value" { t.Error("key with zero ttl should not expire") }
+	})
+
+	t.Run("test largest ttl does not overflow", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.Clock = clock
+
+		cMap.PutWithTTL([]byte("long"), []byte("value"), time.Duration(math.MaxInt64))
+		cMap.PutWithOptions(context.Background(), []byte("long options"), []byte("value"), cmap.CMapPutOptions{ TTL: time.Duration(math.MaxInt64) })
+		clock.Advance(24 * time.Hour)
+
+		if string(cMap.Get([]byte("long"))) != "value" || string(cMap.Get([]byte("long options"))) != "value" { t.Error("key with the largest ttl should not expire") }
+	})
+
+	t.Run("test sweep expired", func(t *testing.T) {
+		inputSize := 1000
+		for idx := range make([]int, inputSize) {
+			randomBytes, _ := GenerateRandomBytes(32)
+			
+			ttl := time.Minute
+			if idx % 2 == 0 { ttl = time.Hour }
+			cMap.PutWithTTL(randomBytes, randomBytes, ttl)
+		}
+
+		if deleted := cMap.SweepExpired(); deleted != 0 {
+			t.Errorf("sweep before expiry should delete nothing: actual(%d)", deleted)
+		}
+
+		clock.Advance(2 * time.Minute)
+
+		deleted := cMap.SweepExpired()
+		t.Logf("deleted: %d", deleted)
+		if deleted != inputSize / 2 {
+			t.Errorf("sweep should delete expired keys: actual(%d), expected(%d)", deleted, inputSize / 2)
+		}
+
+		leaves := cMap.Stats().LeafCount
+		if leaves != inputSize / 2 + 2 {
+			t.Errorf("remaining leaves do not match expected: actual(%d), expected(%d)", leaves, inputSize / 2 + 2)
+		}
+	})
+
+	t.Run("test background sweeper", func(t *testing.T) {
+		cMap.PutWithTTL([]byte("background"), []byte("value"), time.Second)
+		clock.Advance(2 * time.Hour)
+
+		stop := cMap.StartExpirySweeper(time.Millisecond)
+		defer stop()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for cMap.Stats().LeafCount != 2 && time.Now().Before(deadline) { time.Sleep(time.Millisecond) }
+
+		if cMap.Stats().LeafCount != 2 {
+			t.Errorf("background sweeper should delete every expired key: remaining(%d)", cMap.Stats().LeafCount)
+		}
+	})
+
+	t.Log("Done")
+}
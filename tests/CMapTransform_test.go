@@ -0,0 +1,131 @@
+package cmaptests
+
+import "bytes"
+import "fmt"
+import "strconv"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapTransform(t *testing.T) {
+	newMap := func() *cmap.CMap[uint32] {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 5000; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte(strconv.Itoa(i))) }
+
+		return cMap
+	}
+
+	even := func(key, value []byte) bool {
+		i, _ := strconv.Atoi(string(value))
+		return i % 2 == 0
+	}
+
+	t.Run("test filter", func(t *testing.T) {
+		cMap := newMap()
+		filtered := cMap.Filter(even)
+
+		if err := filtered.Verify(); err != nil { t.Fatal(err) }
+		if filtered.Len() != 2500 { t.Errorf("expected 2500 keys, got %d", filtered.Len()) }
+
+		for i := 0; i < 5000; i++ {
+			value := filtered.Get([]byte(fmt.Sprintf("key%d", i)))
+			if (i % 2 == 0) != (value != nil) { t.Errorf("unexpected value for key%d: %s", i, value) }
+		}
+
+		all := cMap.Filter(func(key, value []byte) bool { return true })
+		if all.Len() != cMap.Len() || all.Bytes() != cMap.Bytes() { t.Errorf("expected the full map, got %d keys", all.Len()) }
+		if cMap.Len() != 5000 { t.Errorf("expected the source to be unchanged, got %d keys", cMap.Len()) }
+
+		none := cMap.Filter(func(key, value []byte) bool { return false })
+		if err := none.Verify(); err != nil { t.Fatal(err) }
+		if none.Len() != 0 { t.Errorf("expected an empty map, got %d keys", none.Len()) }
+
+		none.Put([]byte("new"), []byte("value"))
+		if string(none.Get([]byte("new"))) != "value" { t.Error("expected the filtered map to accept writes") }
+	})
+
+	t.Run("test map values", func(t *testing.T) {
+		cMap := newMap()
+		mapped := cMap.MapValues(func(key, value []byte) []byte {
+			if ! even(key, value) { return value }
+			return append([]byte("even-"), value...)
+		})
+
+		if err := mapped.Verify(); err != nil { t.Fatal(err) }
+		if mapped.Len() != 5000 { t.Errorf("expected 5000 keys, got %d", mapped.Len()) }
+
+		for i := 0; i < 5000; i++ {
+			expected := []byte(strconv.Itoa(i))
+			if i % 2 == 0 { expected = []byte("even-" + strconv.Itoa(i)) }
+
+			value := mapped.Get([]byte(fmt.Sprintf("key%d", i)))
+			if ! bytes.Equal(value, expected) { t.Errorf("actual value not equal to expected: actual(%s), expected(%s)", value, expected) }
+		}
+
+		if string(cMap.Get([]byte("key2"))) != "2" { t.Error("expected the source to be unchanged") }
+		if mapped.Bytes() != cMap.Bytes() + 2500 * 5 { t.Errorf("unexpected bytes: %d", mapped.Bytes()) }
+
+		mapped.Put([]byte("key2"), []byte("updated"))
+		if string(cMap.Get([]byte("key2"))) != "2" { t.Error("expected writes to the new map to leave the source unchanged") }
+	})
+
+	t.Run("test partition", func(t *testing.T) {
+		cMap := newMap()
+		in, out := cMap.Partition(even)
+
+		if err := in.Verify(); err != nil { t.Fatal(err) }
+		if err := out.Verify(); err != nil { t.Fatal(err) }
+		if in.Len() != 2500 || out.Len() != 2500 { t.Errorf("expected 2500 keys on each side, got %d and %d", in.Len(), out.Len()) }
+		if in.Bytes() + out.Bytes() != cMap.Bytes() { t.Errorf("expected the bytes to add up, got %d and %d", in.Bytes(), out.Bytes()) }
+
+		for i := 0; i < 5000; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if (i % 2 == 0) != (in.Get(key) != nil) || (i % 2 == 0) == (out.Get(key) != nil) { t.Errorf("key%d on the wrong side", i) }
+		}
+
+		calls := 0
+		cMap.Partition(func(key, value []byte) bool {
+			calls++
+			return true
+		})
+
+		if calls != 5000 { t.Errorf("expected a single call for each key, got %d", calls) }
+	})
+
+	t.Run("test expired and indexed", func(t *testing.T) {
+		clock := newTestClock()
+		cMap := cmap.NewCMap[uint64]()
+		cMap.Clock = clock
+		cMap.EnableOrderedIndex()
+
+		cMap.Put([]byte("a"), []byte("1"))
+		cMap.PutWithTTL([]byte("b"), []byte("2"), time.Minute)
+		cMap.PutWithTTL([]byte("c"), []byte("3"), time.Hour)
+		clock.Advance(2 * time.Minute)
+
+		mapped := cMap.MapValues(func(key, value []byte) []byte { return append(value, '!') })
+		if err := mapped.Verify(); err != nil { t.Fatal(err) }
+		if mapped.Len() != 2 || mapped.Get([]byte("b")) != nil { t.Errorf("expected the expired key to be left out, got %d keys", mapped.Len()) }
+
+		clock.Advance(time.Hour)
+		if mapped.Get([]byte("c")) != nil { t.Error("expected the mapped key to keep its time to live") }
+
+		minKey, _, err := mapped.Min()
+		if err != nil || string(minKey) != "a" { t.Errorf("expected the new map to keep the ordered index, got %s, %v", minKey, err) }
+	})
+	t.Run("test new maps keep the configuration", func(t *testing.T) {
+		source := newConfiguredCMap[uint32]()
+		source.Put([]byte("a"), []byte("1"))
+		source.Put([]byte("b"), []byte("2"))
+
+		keep := func(key, value []byte) bool { return string(key) == "a" }
+		in, out := source.Partition(keep)
+
+		expectSameConfig(t, source, source.Filter(keep))
+		expectSameConfig(t, source, source.MapValues(func(key, value []byte) []byte { return value }))
+		expectSameConfig(t, source, in)
+		expectSameConfig(t, source, out)
+	})
+}
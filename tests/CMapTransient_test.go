@@ -0,0 +1,81 @@
+package cmaptests
+
+import "fmt"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapTransient(t *testing.T) {
+	t.Run("test build", func(t *testing.T) {
+		transient := cmap.NewCMap[uint32]().Transient()
+		for i := 0; i < 10000; i++ { transient.Put([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))) }
+		for i := 0; i < 10000; i += 2 { transient.Delete([]byte(fmt.Sprintf("key%d", i))) }
+		transient.Put([]byte("key1"), []byte("updated"))
+
+		if transient.Len() != 5000 || string(transient.Get([]byte("key1"))) != "updated" || transient.Get([]byte("key0")) != nil { t.Error("unexpected transient contents") }
+		if transient.Delete([]byte("key0")) { t.Error("expected delete of a missing key to report falsey") }
+
+		cMap := transient.Persistent()
+		if cMap.Len() != 5000 || string(cMap.Get([]byte("key3"))) != "value3" { t.Error("unexpected persistent contents") }
+		if err := cMap.Verify(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test source map is unaffected", func(t *testing.T) {
+		source := cmap.NewCMap[uint64]()
+		for i := 0; i < 1000; i++ { source.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		transient := source.Transient()
+		for i := 0; i < 1000; i++ { transient.Put([]byte(fmt.Sprintf("key%d", i)), []byte("changed")) }
+		transient.Delete([]byte("key5"))
+		source.Put([]byte("source only"), []byte("value"))
+
+		if source.Len() != 1001 || string(source.Get([]byte("key1"))) != "value" || source.Get([]byte("key5")) == nil { t.Error("source map was modified by the transient") }
+		if transient.Get([]byte("source only")) != nil { t.Error("transient should not see later writes to the source") }
+		if err := source.Verify(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test persisted map keeps the configuration", func(t *testing.T) {
+		source := newConfiguredCMap[uint64]()
+		transient := source.Transient()
+		transient.Put([]byte("key"), []byte("value"))
+
+		expectSameConfig(t, source, transient.Persistent())
+	})
+
+	t.Run("test persisted map is unaffected by later writes", func(t *testing.T) {
+		transient := cmap.NewCMap[uint32]().Transient()
+		for i := 0; i < 1000; i++ { transient.Put([]byte(fmt.Sprintf("key%d", i)), []byte("first")) }
+
+		first := transient.Persistent()
+		for i := 0; i < 1000; i++ { transient.Put([]byte(fmt.Sprintf("key%d", i)), []byte("second")) }
+		transient.Delete([]byte("key1"))
+		second := transient.Persistent()
+
+		first.Put([]byte("key2"), []byte("concurrent"))
+		if first.Len() != 1000 || string(first.Get([]byte("key1"))) != "first" { t.Error("first map was modified after it was persisted") }
+		if second.Len() != 999 || string(second.Get([]byte("key2"))) != "second" { t.Error("second map was modified by writes to the first") }
+		if err := first.Verify(); err != nil { t.Error(err) }
+		if err := second.Verify(); err != nil { t.Error(err) }
+	})
+}
+
+func BenchmarkCMapTransient(b *testing.B) {
+	keys := make([][]byte, 10000)
+	for i := range keys { keys[i] = []byte(fmt.Sprintf("key%d", i)) }
+
+	b.Run("Put", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			cMap := cmap.NewCMap[uint32]()
+			for _, key := range keys { cMap.Put(key, key) }
+		}
+	})
+
+	b.Run("Transient", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			transient := cmap.NewCMap[uint32]().Transient()
+			for _, key := range keys { transient.Put(key, key) }
+			transient.Persistent()
+		}
+	})
+}
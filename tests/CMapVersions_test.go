@@ -0,0 +1,92 @@
+package cmaptests
+
+import "fmt"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapVersions(t *testing.T) {
+	t.Run("test get at past versions", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.RetainVersions(10)
+
+		versions := []uint64{}
+		for i := 0; i < 5; i++ {
+			cMap.Put([]byte("key"), []byte(fmt.Sprintf("value%d", i)))
+			versions = append(versions, cMap.Seq())
+		}
+
+		cMap.Delete([]byte("key"))
+
+		for i, version := range versions {
+			value, err := cMap.GetAt([]byte("key"), version)
+			if err != nil { t.Fatal(err) }
+			if string(value) != fmt.Sprintf("value%d", i) { t.Errorf("actual value not equal to expected: actual(%s), expected(value%d)", value, i) }
+		}
+
+		value, err := cMap.GetAt([]byte("key"), cMap.Seq())
+		if err != nil || value != nil { t.Errorf("key should be deleted at the current version: %s, %v", value, err) }
+
+		if _, err := cMap.GetAt([]byte("key"), cMap.Seq() + 1); err != cmap.ErrVersionNotRetained { t.Errorf("expected ErrVersionNotRetained, got %v", err) }
+	})
+
+	t.Run("test old versions are released from retention", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		cMap.RetainVersions(3)
+
+		cMap.Put([]byte("key"), []byte("first"))
+		first := cMap.Seq()
+
+		view, err := cMap.ViewAt(first)
+		if err != nil { t.Fatal(err) }
+
+		for i := 0; i < 10; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		if _, err := cMap.GetAt([]byte("key"), first); err != cmap.ErrVersionNotRetained { t.Errorf("expected ErrVersionNotRetained, got %v", err) }
+		if _, err := cMap.GetAt([]byte("key"), cMap.Seq() - 2); err != nil { t.Errorf("last 3 versions should be retained: %v", err) }
+
+		if string(view.Get([]byte("key"))) != "first" || view.Len() != 1 || view.Version() != first { t.Error("view should still read its version") }
+	})
+
+	t.Run("test view range", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 100; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		view := cMap.View()
+		for i := 0; i < 100; i++ { cMap.Delete([]byte(fmt.Sprintf("key%d", i))) }
+
+		count := 0
+		view.Range(func(key []byte, value []byte) bool {
+			count++
+			return true
+		})
+
+		if count != 100 || view.Len() != 100 { t.Errorf("view should hold every key at its version: range(%d), len(%d)", count, view.Len()) }
+		if cMap.Len() != 0 { t.Error("map should be empty at the current version") }
+	})
+
+	t.Run("test concurrent writers", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.RetainVersions(8 * 200)
+		start := cMap.Seq()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 200; i++ { cMap.Put([]byte(fmt.Sprintf("key%d-%d", w, i)), []byte("value")) }
+			}(w)
+		}
+
+		wg.Wait()
+
+		for version := start + 1; version <= cMap.Seq(); version++ {
+			view, err := cMap.ViewAt(version)
+			if err != nil { t.Fatalf("version %d should be retained: %v", version, err) }
+			if view.Len() != int(version - start) { t.Fatalf("version %d should have %d keys, has %d", version, version - start, view.Len()) }
+		}
+	})
+}
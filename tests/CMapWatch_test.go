@@ -0,0 +1,206 @@
+package cmaptests
+
+import "fmt"
+import "sync"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMapWatch(t *testing.T) {
+	t.Run("test watch key", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		sub := cMap.Watch([]byte("hello"), cmap.CMapWatchOptions{})
+		defer sub.Close()
+
+		cMap.Put([]byte("hello"), []byte("world"))
+		cMap.Put([]byte("other"), []byte("value"))
+		cMap.Put([]byte("hello"), []byte("there"))
+		cMap.Delete([]byte("hello"))
+		cMap.Delete([]byte("hello"))
+
+		expected := []cmap.CMapEvent{
+			{ Type: cmap.PutEvent, Key: []byte("hello"), NewValue: []byte("world") },
+			{ Type: cmap.PutEvent, Key: []byte("hello"), OldValue: []byte("world"), NewValue: []byte("there") },
+			{ Type: cmap.DeleteEvent, Key: []byte("hello"), OldValue: []byte("there") },
+		}
+
+		var prevSeq uint64
+		for _, exp := range expected {
+			event := <- sub.Events()
+			if event.Type != exp.Type || string(event.Key) != string(exp.Key) || string(event.OldValue) != string(exp.OldValue) || string(event.NewValue) != string(exp.NewValue) {
+				t.Errorf("actual event not equal to expected: actual(%+v), expected(%+v)", event, exp)
+			}
+
+			if event.Seq <= prevSeq { t.Errorf("sequence should increase: %d after %d", event.Seq, prevSeq) }
+			prevSeq = event.Seq
+		}
+
+		select {
+			case event := <- sub.Events():
+				t.Errorf("unexpected event: %+v", event)
+			default:
+		}
+	})
+
+	t.Run("test watch prefix and subscribe", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint64]()
+		prefixSub := cMap.WatchPrefix([]byte("user:"), cmap.CMapWatchOptions{})
+		allSub := cMap.Subscribe(cmap.CMapWatchOptions{})
+
+		cMap.Put([]byte("user:1"), []byte("a"))
+		cMap.Put([]byte("order:1"), []byte("b"))
+		cMap.Put([]byte("user:2"), []byte("c"))
+
+		prefixSub.Close()
+		allSub.Close()
+
+		prefixEvents, allEvents := 0, 0
+		for range prefixSub.Events() { prefixEvents++ }
+		for range allSub.Events() { allEvents++ }
+
+		if prefixEvents != 2 { t.Errorf("actual prefix events not equal to expected: actual(%d), expected(%d)", prefixEvents, 2) }
+		if allEvents != 3 { t.Errorf("actual events not equal to expected: actual(%d), expected(%d)", allEvents, 3) }
+	})
+
+	t.Run("test on change callback", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+
+		events := []cmap.CMapEvent{}
+		remove := cMap.OnChange(func(event cmap.CMapEvent) { events = append(events, event) })
+
+		cMap.Put([]byte("hello"), []byte("world"))
+		remove()
+		cMap.Put([]byte("hello"), []byte("again"))
+
+		if len(events) != 1 { t.Errorf("actual events not equal to expected: actual(%d), expected(%d)", len(events), 1) }
+	})
+
+	t.Run("test slow consumer policies", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		options := func(policy cmap.SlowConsumerPolicy) cmap.CMapWatchOptions {
+			return cmap.CMapWatchOptions{ BufferSize: 2, Policy: policy }
+		}
+
+		dropNewest := cMap.Subscribe(options(cmap.DropNewest))
+		dropOldest := cMap.Subscribe(options(cmap.DropOldest))
+		disconnect := cMap.Subscribe(options(cmap.Disconnect))
+
+		for i := 0; i < 5; i++ { cMap.Put([]byte("key"), []byte(fmt.Sprintf("value%d", i))) }
+
+		if dropNewest.Dropped() != 3 { t.Errorf("actual dropped not equal to expected: actual(%d), expected(%d)", dropNewest.Dropped(), 3) }
+		if string((<- dropNewest.Events()).NewValue) != "value0" { t.Error("drop newest should keep the oldest events") }
+
+		if dropOldest.Dropped() != 3 { t.Errorf("actual dropped not equal to expected: actual(%d), expected(%d)", dropOldest.Dropped(), 3) }
+		if string((<- dropOldest.Events()).NewValue) != "value3" { t.Error("drop oldest should keep the newest events") }
+
+		received := 0
+		for range disconnect.Events() { received++ }
+		if received != 2 { t.Errorf("disconnected subscription should receive its buffer before closing: received(%d)", received) }
+
+		dropNewest.Close()
+		dropOldest.Close()
+	})
+
+	t.Run("test block policy", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		sub := cMap.Subscribe(cmap.CMapWatchOptions{ BufferSize: 1, Policy: cmap.Block })
+
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 3; i++ { cMap.Put([]byte("key"), []byte(fmt.Sprintf("value%d", i))) }
+			close(done)
+		}()
+
+		select {
+			case <- done:
+				t.Fatal("writer should block on a full subscription")
+			case <- time.After(50 * time.Millisecond):
+		}
+
+		for i := 0; i < 3; i++ {
+			if event := <- sub.Events(); string(event.NewValue) != fmt.Sprintf("value%d", i) { t.Errorf("unexpected event: %+v", event) }
+		}
+
+		<- done
+		sub.Close()
+	})
+
+	t.Run("test per key ordering with concurrent writers", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		sub := cMap.Subscribe(cmap.CMapWatchOptions{ BufferSize: 8 * 1000, Policy: cmap.Block })
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					cMap.Put([]byte(fmt.Sprintf("key%d", i % 10)), []byte(fmt.Sprintf("%d-%d", w, i)))
+				}
+			}(w)
+		}
+
+		wg.Wait()
+		sub.Close()
+
+		last := map[string]string{}
+		var prevSeq uint64
+		total := 0
+		for event := range sub.Events() {
+			if event.Seq <= prevSeq { t.Fatalf("events out of order: %d after %d", event.Seq, prevSeq) }
+			if prev, ok := last[string(event.Key)]; ok && prev != string(event.OldValue) {
+				t.Fatalf("old value does not match previous event for key %s: %s, %s", event.Key, event.OldValue, prev)
+			}
+
+			prevSeq = event.Seq
+			last[string(event.Key)] = string(event.NewValue)
+			total++
+		}
+
+		if total != 8000 { t.Errorf("actual events not equal to expected: actual(%d), expected(%d)", total, 8000) }
+		for key, value := range last {
+			if string(cMap.Get([]byte(key))) != value { t.Errorf("last event does not match value for key %s", key) }
+		}
+	})
+
+	t.Run("test panicking callback does not block writers", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		cMap.OnChange(func(event cmap.CMapEvent) {
+			if string(event.Key) == "poison" { panic("subscriber failed") }
+		})
+
+		func() {
+			defer func() {
+				if recover() == nil { t.Error("expected the callback panic to reach the writer") }
+			}()
+
+			cMap.Put([]byte("poison"), []byte("value"))
+		}()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			var wg sync.WaitGroup
+			for w := 0; w < 4; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < 100; i++ { cMap.Put([]byte(fmt.Sprintf("key%d-%d", w, i)), []byte("value")) }
+				}(w)
+			}
+
+			wg.Wait()
+		}()
+
+		select {
+			case <- done:
+			case <- time.After(5 * time.Second):
+				t.Fatal("writers blocked after a panicking callback")
+		}
+
+		if cMap.Len() != 401 { t.Errorf("actual len not equal to expected: actual(%d), expected(%d)", cMap.Len(), 401) }
+	})
+}
@@ -1,5 +1,6 @@
 package cmaptests
 
+import "bytes"
 import "testing"
 import "sync/atomic"
 
@@ -191,4 +192,36 @@ func TestCMap64(t *testing.T) {
 	})
 	
 	t.Log("done")
-}
\ No newline at end of file
+}
+
+func TestCMapLen(t *testing.T) {
+	cMap := cmap.NewCMap[uint32]()
+
+	cMap.Put([]byte("hello"), []byte("world"))
+	cMap.Put([]byte("new"), []byte("wow!"))
+	cMap.Put([]byte("hello"), []byte("there"))
+
+	if cMap.Len() != 2 { t.Errorf("actual len not equal to expected: actual(%d), expected(%d)", cMap.Len(), 2) }
+	if cMap.Bytes() != 17 { t.Errorf("actual bytes not equal to expected: actual(%d), expected(%d)", cMap.Bytes(), 17) }
+
+	cMap.Delete([]byte("hello"))
+	cMap.Delete([]byte("missing"))
+
+	if cMap.Len() != 1 { t.Errorf("actual len not equal to expected: actual(%d), expected(%d)", cMap.Len(), 1) }
+	if cMap.Bytes() != 7 { t.Errorf("actual bytes not equal to expected: actual(%d), expected(%d)", cMap.Bytes(), 7) }
+}
+
+func TestCMapEmptyValues(t *testing.T) {
+	cMap := cmap.NewCMap[uint64]()
+
+	inlineKey, heapKey := []byte("short"), bytes.Repeat([]byte("k"), 64)
+	for _, key := range [][]byte{ inlineKey, heapKey } {
+		for _, value := range [][]byte{ nil, {} } {
+			leaf := cMap.NewLeafNode(key, value)
+			if leaf.Value() == nil || len(leaf.Value()) != 0 { t.Errorf("expected an empty value for a %d byte key, got %v", len(key), leaf.Value()) }
+
+			cMap.Put(key, value)
+			if got := cMap.Get(key); got == nil || len(got) != 0 { t.Errorf("expected the key of %d bytes to read back an empty value, got %v", len(key), got) }
+		}
+	}
+}
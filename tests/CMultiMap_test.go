@@ -0,0 +1,76 @@
+package cmaptests
+
+import "fmt"
+import "sort"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCMultiMap(t *testing.T) {
+	t.Run("test operations", func(t *testing.T) {
+		multiMap := cmap.NewCMultiMap[uint32]()
+		if ! multiMap.Add([]byte("user:1"), []byte("a")) || ! multiMap.Add([]byte("user:1"), []byte("b")) { t.Error("expected values to be added") }
+		if multiMap.Add([]byte("user:1"), []byte("a")) { t.Error("expected duplicate value to be ignored") }
+		multiMap.Add([]byte("user:2"), []byte("c"))
+
+		values := []string{}
+		for _, value := range multiMap.GetAll([]byte("user:1")) { values = append(values, string(value)) }
+		sort.Strings(values)
+
+		if fmt.Sprint(values) != "[a b]" { t.Errorf("unexpected values: %v", values) }
+		if multiMap.Count([]byte("user:1")) != 2 || multiMap.Count([]byte("missing")) != 0 || multiMap.Len() != 2 { t.Error("unexpected counts") }
+		if ! multiMap.Contains([]byte("user:1"), []byte("b")) || multiMap.Contains([]byte("user:2"), []byte("b")) { t.Error("unexpected contains") }
+
+		if ! multiMap.RemoveValue([]byte("user:1"), []byte("a")) || multiMap.RemoveValue([]byte("user:1"), []byte("a")) { t.Error("expected remove to report only present values") }
+		multiMap.RemoveValue([]byte("user:1"), []byte("b"))
+		if multiMap.GetAll([]byte("user:1")) != nil || multiMap.Len() != 1 { t.Error("expected key to be removed with its last value") }
+
+		if ! multiMap.Delete([]byte("user:2")) || multiMap.Len() != 0 { t.Error("expected key to be deleted") }
+	})
+
+	t.Run("test many values", func(t *testing.T) {
+		multiMap := cmap.NewCMultiMap[uint64]()
+		for i := 0; i < 5000; i++ { multiMap.Add([]byte(fmt.Sprintf("key%d", i % 10)), []byte(fmt.Sprintf("value%d", i))) }
+
+		total := 0
+		multiMap.Range(func(key []byte, values [][]byte) bool {
+			if len(values) != 500 { t.Errorf("expected 500 values for %s, got %d", key, len(values)) }
+			total += len(values)
+			return true
+		})
+
+		if total != 5000 { t.Errorf("expected 5000 values, got %d", total) }
+	})
+
+	t.Run("test stats", func(t *testing.T) {
+		multiMap := cmap.NewCMultiMap[uint64]()
+		multiMap.Add([]byte("key"), []byte("value"))
+
+		before := multiMap.Stats()
+		for i := 0; i < 100; i++ { multiMap.Add([]byte("key"), []byte(fmt.Sprintf("value%03d", i))) }
+		multiMap.Add([]byte("other"), []byte("value"))
+
+		stats := multiMap.Stats()
+		if stats.LeafCount != 2 || stats.KeyBytes != 8 { t.Errorf("expected 2 keys of 8 bytes, got %d, %d", stats.LeafCount, stats.KeyBytes) }
+		if stats.ValueBytes != 5 + 100 * 8 + 5 { t.Errorf("expected the values to count towards value bytes, got %d", stats.ValueBytes) }
+		if stats.NodeOverheadBytes <= before.NodeOverheadBytes * 2 { t.Errorf("expected the trie of values to count towards overhead, got %d then %d", before.NodeOverheadBytes, stats.NodeOverheadBytes) }
+	})
+
+	t.Run("test concurrent adds", func(t *testing.T) {
+		multiMap := cmap.NewCMultiMap[uint32]()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ { multiMap.Add([]byte("shared"), []byte(fmt.Sprintf("%d-%d", w, i))) }
+			}(w)
+		}
+
+		wg.Wait()
+		if multiMap.Count([]byte("shared")) != 4000 || len(multiMap.GetAll([]byte("shared"))) != 4000 { t.Errorf("expected 4000 values, got %d", multiMap.Count([]byte("shared"))) }
+	})
+}
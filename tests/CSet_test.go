@@ -0,0 +1,113 @@
+package cmaptests
+
+import "fmt"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCSet(t *testing.T) {
+	newSet := func(from, to int) *cmap.CSet[uint32] {
+		set := cmap.NewCSet[uint32]()
+		for i := from; i < to; i++ { set.Add([]byte(fmt.Sprintf("key%d", i))) }
+
+		return set
+	}
+
+	t.Run("test operations", func(t *testing.T) {
+		set := cmap.NewCSet[uint64]()
+		if ! set.Add([]byte("a")) || set.Add([]byte("a")) { t.Error("expected add to report only new keys") }
+		set.Add([]byte("b"))
+
+		if ! set.Contains([]byte("a")) || set.Contains([]byte("c")) { t.Error("unexpected contains") }
+		if set.Len() != 2 { t.Errorf("expected 2 keys, got %d", set.Len()) }
+		if ! set.Remove([]byte("a")) || set.Remove([]byte("a")) { t.Error("expected remove to report only present keys") }
+
+		keys := []string{}
+		set.Range(func(key []byte) bool {
+			keys = append(keys, string(key))
+			return true
+		})
+
+		if len(keys) != 1 || keys[0] != "b" { t.Errorf("unexpected keys: %v", keys) }
+	})
+
+	t.Run("test set algebra", func(t *testing.T) {
+		a, b := newSet(0, 3000), newSet(2000, 5000)
+
+		union := a.Union(b)
+		intersection := a.Intersection(b)
+		difference := a.Difference(b)
+
+		if union.Len() != 5000 || intersection.Len() != 1000 || difference.Len() != 2000 { t.Fatalf("unexpected sizes: %d, %d, %d", union.Len(), intersection.Len(), difference.Len()) }
+		for i := 0; i < 5000; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if ! union.Contains(key) { t.Fatalf("%s missing from union", key) }
+			if intersection.Contains(key) != (i >= 2000 && i < 3000) { t.Fatalf("unexpected intersection membership for %s", key) }
+			if difference.Contains(key) != (i < 2000) { t.Fatalf("unexpected difference membership for %s", key) }
+		}
+
+		for _, set := range []*cmap.CSet[uint32]{ union, intersection, difference } {
+			if err := set.Verify(); err != nil { t.Error(err) }
+		}
+
+		if a.Len() != 3000 || b.Len() != 5000 - 2000 { t.Error("operands should be unchanged") }
+	})
+
+	t.Run("test shared subtrees", func(t *testing.T) {
+		a := newSet(0, 1000)
+		b := a.Union(cmap.NewCSet[uint32]())
+		b.Add([]byte("extra"))
+
+		if a.Union(a).Len() != 1000 || a.Intersection(a).Len() != 1000 || a.Difference(a).Len() != 0 { t.Error("unexpected self operations") }
+		if a.Union(b).Len() != 1001 || b.Difference(a).Len() != 1 || a.Intersection(b).Len() != 1000 { t.Error("unexpected operations on derived sets") }
+		if err := b.Difference(a).Verify(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test union counts only added keys", func(t *testing.T) {
+		a := newSet(0, 1000)
+		b := a.Union(cmap.NewCSet[uint32]())
+		for i := 1000; i < 1100; i++ { b.Add([]byte(fmt.Sprintf("key%d", i))) }
+		for i := 0; i < 50; i++ { b.Remove([]byte(fmt.Sprintf("key%d", i))) }
+
+		single := cmap.NewCSet[uint32]()
+		single.Add([]byte("key0"))
+
+		for name, union := range map[string]*cmap.CSet[uint32]{ "a, b": a.Union(b), "b, a": b.Union(a), "a, single": a.Union(single), "single, a": single.Union(a), "single, b": single.Union(b) } {
+			expected := 1100
+			if name == "a, single" || name == "single, a" { expected = 1000 }
+			if name == "single, b" { expected = 1051 }
+
+			if union.Len() != expected { t.Errorf("%s: expected %d keys, got %d", name, expected, union.Len()) }
+			if err := union.Verify(); err != nil { t.Errorf("%s: %v", name, err) }
+		}
+	})
+
+	t.Run("test concurrent adds", func(t *testing.T) {
+		set := cmap.NewCSet[uint32]()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ { set.Add([]byte(fmt.Sprintf("key%d", i))) }
+			}()
+		}
+
+		wg.Wait()
+		if set.Len() != 1000 { t.Errorf("expected 1000 keys, got %d", set.Len()) }
+	})
+}
+
+func BenchmarkCSetUnionShared(b *testing.B) {
+	base := cmap.NewCSet[uint32]()
+	for i := 0; i < 100000; i++ { base.Add([]byte(fmt.Sprintf("key%d", i))) }
+
+	changed := base.Union(cmap.NewCSet[uint32]())
+	changed.Add([]byte("extra"))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ { base.Union(changed) }
+}
@@ -0,0 +1,112 @@
+package cmaptests
+
+import "context"
+import "fmt"
+import "sync"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestCounterMap(t *testing.T) {
+	t.Run("test operations", func(t *testing.T) {
+		counters := cmap.NewCounterMap[uint32, int64]()
+		if value, ok := counters.Add([]byte("requests"), 5); ! ok || value != 5 { t.Error("unexpected add results") }
+		if value, ok := counters.Add([]byte("requests"), -2); ! ok || value != 3 { t.Error("unexpected add results") }
+		if counters.Get([]byte("requests")) != 3 || counters.Get([]byte("missing")) != 0 { t.Error("unexpected get results") }
+
+		if counters.Reset([]byte("requests")) != 3 || counters.Len() != 0 { t.Error("expected counter to be reset") }
+		if value, _ := counters.Add([]byte("requests"), 1); value != 1 { t.Error("expected counter to restart from 0") }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		value, err := counters.AddContext(ctx, []byte("requests"), 1)
+		if err != context.Canceled || value != 0 { t.Errorf("expected the cancelled add to fail, got %d, %v", value, err) }
+		if counters.Get([]byte("requests")) != 1 { t.Error("expected the cancelled add to leave the counter unchanged") }
+
+		latencies := cmap.NewCounterMap[uint64, float64]()
+		latencies.Add([]byte("p99"), 1.5)
+		if value, _ := latencies.Add([]byte("p99"), 0.25); value != 1.75 { t.Error("unexpected float counter") }
+	})
+
+	t.Run("test stats", func(t *testing.T) {
+		counters := cmap.NewCounterMap[uint32, int64]()
+		latencies := cmap.NewCounterMap[uint64, float64]()
+		for i := 0; i < 100; i++ {
+			counters.Add([]byte(fmt.Sprintf("key%03d", i)), 1)
+			latencies.Add([]byte(fmt.Sprintf("key%03d", i)), 0.5)
+		}
+
+		for _, stats := range []*cmap.CMapStats{ counters.Stats(), latencies.Stats() } {
+			if stats.LeafCount != 100 || stats.KeyBytes != 600 { t.Errorf("expected 100 keys of 6 bytes, got %d, %d", stats.LeafCount, stats.KeyBytes) }
+			if stats.ValueBytes != 800 { t.Errorf("expected each counter to count 8 value bytes, got %d", stats.ValueBytes) }
+			if stats.NodeOverheadBytes == 0 { t.Error("expected the counters to count towards overhead") }
+		}
+	})
+
+	t.Run("test concurrent adds", func(t *testing.T) {
+		counters := cmap.NewCounterMap[uint64, int64]()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ { counters.Add([]byte(fmt.Sprintf("label%d", i % 10)), 1) }
+			}()
+		}
+
+		wg.Wait()
+
+		total := int64(0)
+		counters.Range(func(key []byte, value int64) bool {
+			total += value
+			return true
+		})
+
+		if total != 8000 || counters.Get([]byte("label0")) != 800 { t.Errorf("unexpected totals: %d, %d", total, counters.Get([]byte("label0"))) }
+	})
+
+	t.Run("test drain", func(t *testing.T) {
+		counters := cmap.NewCounterMap[uint32, int64]()
+		stop := make(chan struct{})
+
+		var wg sync.WaitGroup
+		var added [4]int64
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for {
+					select {
+						case <-stop:
+							return
+						default:
+							counters.Add([]byte(fmt.Sprintf("label%d", added[w] % 16)), 1)
+							added[w]++
+					}
+				}
+			}(w)
+		}
+
+		drainedTotal := int64(0)
+		for i := 0; i < 50; i++ {
+			counters.Drain().Range(func(key []byte, value int64) bool {
+				drainedTotal += value
+				return true
+			})
+		}
+
+		close(stop)
+		wg.Wait()
+
+		counters.Range(func(key []byte, value int64) bool {
+			drainedTotal += value
+			return true
+		})
+
+		expected := added[0] + added[1] + added[2] + added[3]
+		if drainedTotal != expected { t.Errorf("expected every add to be counted once, counted %d of %d", drainedTotal, expected) }
+	})
+}
@@ -0,0 +1,78 @@
+package cmaptests
+
+import "fmt"
+import "testing"
+
+import "github.com/sirgallo/cmap"
+
+
+func TestPMap(t *testing.T) {
+	t.Run("test with and without", func(t *testing.T) {
+		empty := cmap.NewPMap[uint32]()
+		one := empty.With([]byte("hi"), []byte("world"))
+		two := one.With([]byte("hi"), []byte("there")).With([]byte("bye"), []byte("now"))
+		removed := two.Without([]byte("hi"))
+
+		if empty.Len() != 0 || empty.Get([]byte("hi")) != nil { t.Error("empty map should be unchanged") }
+		if one.Len() != 1 || string(one.Get([]byte("hi"))) != "world" { t.Error("earlier map should be unchanged") }
+		if two.Len() != 2 || string(two.Get([]byte("hi"))) != "there" || two.Bytes() != 13 { t.Errorf("unexpected map: %d entries, %d bytes", two.Len(), two.Bytes()) }
+		if removed.Len() != 1 || removed.Get([]byte("hi")) != nil || string(removed.Get([]byte("bye"))) != "now" { t.Error("unexpected map after without") }
+		if removed.Without([]byte("missing")).Len() != 1 { t.Error("removing a missing key should not change the map") }
+	})
+
+	t.Run("test zero value", func(t *testing.T) {
+		var empty cmap.PMap[uint64]
+		if empty.Len() != 0 || empty.Bytes() != 0 || empty.Get([]byte("hi")) != nil || empty.Without([]byte("hi")).Len() != 0 { t.Error("zero value should be an empty map") }
+
+		empty.Range(func(key, value []byte) bool {
+			t.Errorf("unexpected key in the zero value: %s", key)
+			return true
+		})
+
+		one := empty.With([]byte("hi"), []byte("world"))
+		if one.Len() != 1 || string(one.Get([]byte("hi"))) != "world" || empty.Len() != 0 { t.Error("unexpected map made from the zero value") }
+		if err := empty.CMap().Verify(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test many versions", func(t *testing.T) {
+		versions := []cmap.PMap[uint64]{ cmap.NewPMap[uint64]() }
+		for i := 0; i < 2000; i++ {
+			versions = append(versions, versions[len(versions) - 1].With([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i))))
+		}
+
+		for i := 0; i < 2000; i += 100 {
+			version := versions[i]
+			if version.Len() != i { t.Fatalf("version %d has %d entries", i, version.Len()) }
+			if i > 0 && version.Get([]byte(fmt.Sprintf("key%d", i - 1))) == nil { t.Fatalf("version %d is missing its last key", i) }
+			if version.Get([]byte(fmt.Sprintf("key%d", i))) != nil { t.Fatalf("version %d has a later key", i) }
+		}
+
+		final := versions[len(versions) - 1]
+		for i := 0; i < 2000; i += 2 { final = final.Without([]byte(fmt.Sprintf("key%d", i))) }
+		if final.Len() != 1000 { t.Errorf("expected 1000 entries, got %d", final.Len()) }
+		if err := final.CMap().Verify(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test conversion", func(t *testing.T) {
+		cMap := cmap.NewCMap[uint32]()
+		for i := 0; i < 100; i++ { cMap.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value")) }
+
+		pMap := cMap.PMap()
+		cMap.Put([]byte("later"), []byte("value"))
+		cMap.Delete([]byte("key0"))
+
+		if pMap.Len() != 100 || pMap.Get([]byte("later")) != nil || pMap.Get([]byte("key0")) == nil { t.Error("immutable map should not see later writes") }
+
+		converted := pMap.With([]byte("extra"), []byte("value")).CMap()
+		converted.Put([]byte("another"), []byte("value"))
+		if converted.Len() != 102 || cMap.Get([]byte("extra")) != nil || pMap.Len() != 100 { t.Error("converted map should be independent") }
+		if err := converted.Verify(); err != nil { t.Error(err) }
+	})
+
+	t.Run("test conversion keeps the configuration", func(t *testing.T) {
+		source := newConfiguredCMap[uint32]()
+		source.Put([]byte("key"), []byte("value"))
+
+		expectSameConfig(t, source, source.PMap().With([]byte("other"), []byte("value")).CMap())
+	})
+}
@@ -0,0 +1,215 @@
+package cmaptests
+
+import "bufio"
+import "fmt"
+import "net"
+import "reflect"
+import "sort"
+import "strings"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
+import "github.com/sirgallo/cmap/resp"
+
+
+func startRESPServer(t *testing.T) (*cmap.CMap[uint64], *resp.Client) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil { t.Fatal(err) }
+
+	cMap := cmap.NewCMap[uint64]()
+	server := resp.NewServer(cMap)
+	go server.Serve(listener)
+
+	client, err := resp.Dial(listener.Addr().String())
+	if err != nil { t.Fatal(err) }
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	return cMap, client
+}
+
+func expectReply(t *testing.T, client *resp.Client, expected any, args ...string) {
+	t.Helper()
+
+	reply, err := client.Do(args...)
+	if err != nil { t.Fatalf("%v: unexpected error: %v", args, err) }
+	if bulk, ok := reply.([]byte); ok { reply = string(bulk) }
+	if ! reflect.DeepEqual(reply, expected) { t.Errorf("%v: actual reply not equal to expected: actual(%#v), expected(%#v)", args, reply, expected) }
+}
+
+func TestRESPServer(t *testing.T) {
+	t.Run("test get set del exists", func(t *testing.T) {
+		_, client := startRESPServer(t)
+
+		expectReply(t, client, "PONG", "PING")
+		expectReply(t, client, "OK", "SET", "hello", "world")
+		expectReply(t, client, "world", "GET", "hello")
+		expectReply(t, client, nil, "GET", "missing")
+		expectReply(t, client, int64(2), "EXISTS", "hello", "hello", "missing")
+		expectReply(t, client, int64(1), "DEL", "hello", "missing")
+		expectReply(t, client, int64(0), "EXISTS", "hello")
+		expectReply(t, client, int64(0), "DBSIZE")
+	})
+
+	t.Run("test set conditions and expiry", func(t *testing.T) {
+		cMap, client := startRESPServer(t)
+
+		expectReply(t, client, nil, "SET", "key", "value", "XX")
+		expectReply(t, client, "OK", "SET", "key", "value", "NX")
+		expectReply(t, client, nil, "SET", "key", "other", "NX")
+		expectReply(t, client, "OK", "SET", "key", "other", "XX")
+		expectReply(t, client, "other", "GET", "key")
+
+		clock := newTestClock()
+		cMap.Clock = clock
+
+		expectReply(t, client, "OK", "SET", "session", "token", "EX", "10")
+		expectReply(t, client, "token", "GET", "session")
+		clock.Advance(10 * time.Second)
+		expectReply(t, client, nil, "GET", "session")
+		expectReply(t, client, "OK", "SET", "session", "new", "NX", "PX", "500")
+
+		for _, args := range [][]string{ { "SET", "key", "value", "NX", "XX" }, { "SET", "key", "value", "EX" }, { "SET", "key", "value", "BOGUS" } } {
+			if _, err := client.Do(args...); err == nil { t.Errorf("%v: expected syntax error", args) }
+		}
+
+		if _, err := client.Do("SET", "key", "value", "EX", "0"); err == nil { t.Error("expected invalid expire time error") }
+		if _, err := client.Do("SET", "key", "value", "EX", "9223372037"); err == nil { t.Error("expected invalid expire time error for an overflowing ttl") }
+		if _, err := client.Do("SET", "key", "value", "PX", "9223372036855"); err == nil { t.Error("expected invalid expire time error for an overflowing ttl") }
+
+		expectReply(t, client, "OK", "SET", "forever", "value", "EX", "9223372036")
+		clock.Advance(24 * time.Hour)
+		expectReply(t, client, "value", "GET", "forever")
+	})
+
+	t.Run("test mget mset", func(t *testing.T) {
+		_, client := startRESPServer(t)
+
+		expectReply(t, client, "OK", "MSET", "a", "1", "b", "2")
+		expectReply(t, client, []any{ []byte("1"), nil, []byte("2") }, "MGET", "a", "missing", "b")
+		expectReply(t, client, int64(2), "DBSIZE")
+
+		if _, err := client.Do("MSET", "a", "1", "b"); err == nil { t.Error("expected wrong number of arguments error") }
+	})
+
+	t.Run("test scan", func(t *testing.T) {
+		_, client := startRESPServer(t)
+
+		expected := []string{}
+		for i := 0; i < 95; i++ {
+			key := fmt.Sprintf("key%d", i)
+			client.Do("SET", key, "value")
+			if i % 10 == 3 { expected = append(expected, key) }
+		}
+
+		client.Do("SET", "other", "value")
+
+		scanned := []string{}
+		cursor := "0"
+		for {
+			reply, err := client.Do("SCAN", cursor, "MATCH", "key*3", "COUNT", "7")
+			if err != nil { t.Fatal(err) }
+
+			page := reply.([]any)
+			for _, key := range page[1].([]any) { scanned = append(scanned, string(key.([]byte))) }
+
+			cursor = string(page[0].([]byte))
+			if cursor == "0" { break }
+		}
+
+		sort.Strings(expected)
+		sort.Strings(scanned)
+		if ! reflect.DeepEqual(scanned, expected) { t.Errorf("actual keys not equal to expected: actual(%v), expected(%v)", scanned, expected) }
+	})
+
+	t.Run("test scan match", func(t *testing.T) {
+		_, client := startRESPServer(t)
+		for _, key := range []string{ "user/1", "user/2", "user*3", "users", "admin/1", "b", "c", "[x]" } { client.Do("SET", key, "value") }
+
+		match := func(pattern string) []string {
+			reply, err := client.Do("SCAN", "0", "MATCH", pattern, "COUNT", "100")
+			if err != nil { t.Fatal(err) }
+
+			keys := []string{}
+			for _, key := range reply.([]any)[1].([]any) { keys = append(keys, string(key.([]byte))) }
+
+			sort.Strings(keys)
+			return keys
+		}
+
+		expected := map[string][]string{
+			"user*": { "user*3", "user/1", "user/2", "users" },
+			"*/1": { "admin/1", "user/1" },
+			`user\*3`: { "user*3" },
+			"user?[12]": { "user/1", "user/2" },
+			"[^u]*": { "[x]", "admin/1", "b", "c" },
+			"[a-c]": { "b", "c" },
+			`\[x\]`: { "[x]" },
+			"[": {},
+		}
+
+		for pattern, keys := range expected {
+			if actual := match(pattern); ! reflect.DeepEqual(actual, keys) { t.Errorf("%s: actual keys not equal to expected: actual(%v), expected(%v)", pattern, actual, keys) }
+		}
+	})
+
+	t.Run("test resp3", func(t *testing.T) {
+		_, client := startRESPServer(t)
+
+		reply, err := client.Do("HELLO", "3")
+		if err != nil { t.Fatal(err) }
+
+		info, ok := reply.(map[string]any)
+		if ! ok || info["proto"] != int64(3) || string(info["server"].([]byte)) != "cmap" { t.Errorf("unexpected hello reply: %#v", reply) }
+
+		expectReply(t, client, nil, "GET", "missing")
+		expectReply(t, client, nil, "SET", "key", "value", "XX")
+
+		if _, err := client.Do("HELLO", "4"); err == nil { t.Error("expected unsupported protocol version error") }
+	})
+
+	t.Run("test inline commands", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil { t.Fatal(err) }
+
+		cMap := cmap.NewCMap[uint64]()
+		server := resp.NewServer(cMap)
+		go server.Serve(listener)
+		t.Cleanup(func() { server.Close() })
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil { t.Fatal(err) }
+		defer conn.Close()
+
+		firstKey, firstValue := strings.Repeat("k", 40), strings.Repeat("v", 40)
+		secondKey, secondValue := strings.Repeat("x", 40), strings.Repeat("y", 40)
+		fmt.Fprintf(conn, "SET %s %s\r\nSET %s %s\r\n", firstKey, firstValue, secondKey, secondValue)
+
+		reader := bufio.NewReader(conn)
+		for range []int{ 0, 1 } {
+			line, err := reader.ReadString('\n')
+			if err != nil || line != "+OK\r\n" { t.Fatalf("unexpected reply: %q, %v", line, err) }
+		}
+
+		fmt.Fprintf(conn, "GET %s\r\n", firstKey)
+		if _, err := reader.ReadString('\n'); err != nil { t.Fatal(err) }
+
+		value, err := reader.ReadString('\n')
+		if err != nil || value != firstValue + "\r\n" { t.Errorf("actual value not equal to expected: actual(%q), expected(%q)", value, firstValue) }
+		if string(cMap.Get([]byte(firstKey))) != firstValue { t.Errorf("stored value overwritten: %s", cMap.Get([]byte(firstKey))) }
+	})
+
+	t.Run("test errors and quit", func(t *testing.T) {
+		_, client := startRESPServer(t)
+
+		if _, err := client.Do("BOGUS"); err == nil { t.Error("expected unknown command error") }
+		if _, err := client.Do("GET"); err == nil { t.Error("expected wrong number of arguments error") }
+
+		expectReply(t, client, "OK", "QUIT")
+		if _, err := client.Do("PING"); err == nil { t.Error("connection should be closed after quit") }
+	})
+}
@@ -1,6 +1,10 @@
 package cmaptests
 
 import "crypto/rand"
+import "testing"
+import "time"
+
+import "github.com/sirgallo/cmap"
 
 
 type KeyVal struct {
@@ -17,4 +21,31 @@ func GenerateRandomBytes(length int) ([]byte, error) {
 	}
 
 	return randomBytes, nil
-}
\ No newline at end of file
+}
+
+// configClock is the clock of maps made by newConfiguredCMap
+type configClock struct {
+	offset time.Duration
+}
+
+func (clock *configClock) Now() time.Time { return time.Now().Add(clock.offset) }
+
+// newConfiguredCMap creates a map with every configuration field set, for checking that maps made from it keep them
+func newConfiguredCMap[T uint32 | uint64]() *cmap.CMap[T] {
+	cMap := cmap.NewCMap[T]()
+	cMap.Metrics = cmap.NewCMapMetrics()
+	cMap.Backoff = cmap.ExponentialBackoff{ Base: time.Microsecond, Max: time.Millisecond }
+	cMap.MaxRetries = 7
+	cMap.Clock = &configClock{}
+
+	return cMap
+}
+
+// expectSameConfig fails the test if a map does not have the configuration of the map it was made from
+func expectSameConfig[T uint32 | uint64](t *testing.T, source *cmap.CMap[T], cMap *cmap.CMap[T]) {
+	t.Helper()
+
+	if cMap.Metrics != source.Metrics || cMap.Backoff != source.Backoff || cMap.MaxRetries != source.MaxRetries || cMap.Clock != source.Clock {
+		t.Errorf("expected the configuration of the source map: actual(%v, %v, %d, %v), expected(%v, %v, %d, %v)", cMap.Metrics, cMap.Backoff, cMap.MaxRetries, cMap.Clock, source.Metrics, source.Backoff, source.MaxRetries, source.Clock)
+	}
+}